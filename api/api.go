@@ -153,6 +153,17 @@ type VehicleFinishTimer interface {
 	FinishTime() (time.Time, error)
 }
 
+// ChargeCurvePoint is the vehicle's maximum charge power in W at soc
+type ChargeCurvePoint struct {
+	SoC   float64 `mapstructure:"soc" json:"soc"`
+	Power float64 `mapstructure:"power" json:"power"`
+}
+
+// VehicleChargeCurve provides the vehicle's maximum charge power depending on soc
+type VehicleChargeCurve interface {
+	ChargeCurve() []ChargeCurvePoint
+}
+
 // VehicleRange provides the vehicles remaining km range
 type VehicleRange interface {
 	Range() (int64, error)
@@ -163,6 +174,12 @@ type VehicleClimater interface {
 	Climater() (active bool, outsideTemp float64, targetTemp float64, err error)
 }
 
+// VehicleClimateControl starts and stops the vehicle's climatisation
+type VehicleClimateControl interface {
+	StartClimate(targetTemp float64) error // vehicle default target temperature if zero
+	StopClimate() error
+}
+
 // VehicleOdometer returns the vehicles milage
 type VehicleOdometer interface {
 	Odometer() (float64, error)
@@ -183,6 +200,11 @@ type VehicleStopCharge interface {
 	StopCharge() error
 }
 
+// VehicleChargeLimit sets the vehicle's on-board charge limit
+type VehicleChargeLimit interface {
+	SetChargeLimit(soc int) error
+}
+
 // AlarmClock provides wakeup calls to the vehicle with an API call or a CP interrupt from the charger
 type AlarmClock interface {
 	WakeUp() error
@@ -193,6 +215,20 @@ type Tariff interface {
 	CurrentPrice() (float64, error) // EUR/kWh, CHF/kWh, ...
 }
 
+// Rate is a single value valid for the given time slot
+type Rate struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Value float64   `json:"value"`
+}
+
+// CO2Intensity provides current and forecast grid CO2 intensity
+type CO2Intensity interface {
+	IsClean() (bool, error)
+	CurrentIntensity() (float64, error) // gCO2/kWh
+	Forecast() ([]Rate, error)
+}
+
 type WebController interface {
 	WebControl(*mux.Router)
 }
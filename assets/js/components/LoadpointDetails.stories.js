@@ -43,6 +43,7 @@ VehicleRange.args = {
   chargeDuration: 95 * 60,
   vehiclePresent: true,
   vehicleRange: 240.123,
+  vehicleRangeAge: 600,
   chargeRemainingDuration: 5 * 3600,
 };
 
@@ -40,6 +40,7 @@ type Alfen struct {
 	mu      sync.Mutex
 	curr    float64
 	enabled bool
+	done    chan struct{}
 }
 
 const (
@@ -86,6 +87,7 @@ func NewAlfen(uri string, slaveID uint8) (api.Charger, error) {
 	wb := &Alfen{
 		log:  log,
 		conn: conn,
+		done: make(chan struct{}),
 	}
 
 	go wb.heartbeat()
@@ -95,7 +97,16 @@ func NewAlfen(uri string, slaveID uint8) (api.Charger, error) {
 
 // heartbeat implements the api.ChargerEx interface
 func (wb *Alfen) heartbeat() {
-	for range time.NewTicker(time.Minute).C {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wb.done:
+			return
+		case <-ticker.C:
+		}
+
 		wb.mu.Lock()
 		var curr float64
 		if wb.enabled {
@@ -246,3 +257,9 @@ func (c *Alfen) Phases1p3p(phases int) error {
 // 		fmt.Printf("Firmware: %0 x\n", b)
 // 	}
 // }
+
+// Close stops the heartbeat
+func (wb *Alfen) Close() error {
+	close(wb.done)
+	return nil
+}
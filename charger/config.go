@@ -26,6 +26,12 @@ func (r chargerRegistry) Get(name string) (func(map[string]interface{}) (api.Cha
 
 var registry chargerRegistry = make(map[string]func(map[string]interface{}) (api.Charger, error))
 
+// Registered returns true if the charger type is registered
+func Registered(typ string) bool {
+	_, err := registry.Get(strings.ToLower(typ))
+	return err == nil
+}
+
 // NewFromConfig creates charger from configuration
 func NewFromConfig(typ string, other map[string]interface{}) (v api.Charger, err error) {
 	factory, err := registry.Get(strings.ToLower(typ))
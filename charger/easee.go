@@ -53,6 +53,7 @@ type Easee struct {
 	phaseMode             int
 	currentPower, sessionEnergy,
 	currentL1, currentL2, currentL3 float64
+	cancel                          context.CancelFunc
 }
 
 func init() {
@@ -148,7 +149,11 @@ func NewEasee(user, password, charger string, cache time.Duration) (*Easee, erro
 		}
 	}
 
-	client, err := signalr.NewClient(context.Background(),
+	// client loop ends when context is cancelled
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+
+	client, err := signalr.NewClient(ctx,
 		signalr.WithConnector(c.connect(ts)),
 		signalr.WithReceiver(c),
 		signalr.Logger(easee.SignalrLogger(c.log.TRACE), false),
@@ -496,3 +501,11 @@ func (c *Easee) Currents() (float64, float64, float64, error) {
 
 	return c.currentL1, c.currentL2, c.currentL3, nil
 }
+
+// Close stops the signalR client
+func (c *Easee) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
@@ -31,6 +31,7 @@ type Keba struct {
 	timeout time.Duration
 	recv    chan keba.UDPMsg
 	sender  *keba.Sender
+	serial  string
 }
 
 func init() {
@@ -101,6 +102,7 @@ func NewKeba(uri, serial string, rfid RFID, timeout time.Duration) (*Keba, error
 		serial = conn
 	}
 
+	c.serial = serial
 	keba.Instance.Subscribe(serial, c.recv)
 
 	return c, err
@@ -335,3 +337,9 @@ func (c *Keba) Diagnose() {
 		fmt.Printf("%+v\n", kr)
 	}
 }
+
+// Close unsubscribes from the listener
+func (c *Keba) Close() error {
+	keba.Instance.Unsubscribe(c.serial, c.recv)
+	return nil
+}
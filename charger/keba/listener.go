@@ -39,7 +39,7 @@ type Listener struct {
 	mux     sync.Mutex
 	log     *util.Logger
 	conn    *net.UDPConn
-	clients map[string]chan<- UDPMsg
+	clients map[string][]chan<- UDPMsg
 	cache   map[string]string
 }
 
@@ -58,7 +58,7 @@ func New(log *util.Logger) (*Listener, error) {
 	l := &Listener{
 		log:     log,
 		conn:    conn,
-		clients: make(map[string]chan<- UDPMsg),
+		clients: make(map[string][]chan<- UDPMsg),
 		cache:   make(map[string]string),
 	}
 
@@ -67,12 +67,32 @@ func New(log *util.Logger) (*Listener, error) {
 	return l, nil
 }
 
-// Subscribe adds a client address or serial and message channel to the list of subscribers
+// Subscribe adds a client address or serial and message channel to the list of subscribers.
+// Multiple channels may subscribe to the same address, e.g. while a charger is replaced on reload.
 func (l *Listener) Subscribe(addr string, c chan<- UDPMsg) {
 	l.mux.Lock()
 	defer l.mux.Unlock()
 
-	l.clients[addr] = c
+	l.clients[addr] = append(l.clients[addr], c)
+}
+
+// Unsubscribe removes the message channel of a client address or serial from the list of subscribers
+func (l *Listener) Unsubscribe(addr string, c chan<- UDPMsg) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	var res []chan<- UDPMsg
+	for _, client := range l.clients[addr] {
+		if client != c {
+			res = append(res, client)
+		}
+	}
+
+	if len(res) > 0 {
+		l.clients[addr] = res
+	} else {
+		delete(l.clients, addr)
+	}
 }
 
 func (l *Listener) listen() {
@@ -138,12 +158,14 @@ func (l *Listener) send(msg UDPMsg) {
 	l.mux.Lock()
 	defer l.mux.Unlock()
 
-	for addr, client := range l.clients {
+	for addr, clients := range l.clients {
 		if l.addrMatches(addr, msg) {
-			select {
-			case client <- msg:
-			default:
-				l.log.TRACE.Println("recv: listener blocked")
+			for _, client := range clients {
+				select {
+				case client <- msg:
+				default:
+					l.log.TRACE.Println("recv: listener blocked")
+				}
 			}
 			break
 		}
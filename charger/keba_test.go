@@ -0,0 +1,70 @@
+package charger
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// kebaReport sends reports of serial to the keba listener until a message is received by c
+func kebaReport(t *testing.T, c *Keba, serial string) bool {
+	conn, err := net.Dial("udp", "127.0.0.1:7090")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		if _, err := conn.Write([]byte(`{"ID":"1","Serial":"` + serial + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case msg := <-c.recv:
+			return msg.Report != nil && msg.Report.Serial == serial
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+func TestKebaReplaceSameSerial(t *testing.T) {
+	old, err := NewKeba("127.0.0.1", "1001", RFID{}, time.Second)
+	if err != nil {
+		t.Skip("keba listener not available:", err)
+	}
+
+	// reload replaces charger with same serial before closing the old one
+	replaced, err := NewKeba("127.0.0.1", "1001", RFID{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = old.Close()
+
+	if !kebaReport(t, replaced, "1001") {
+		t.Error("replacement charger not receiving reports")
+	}
+
+	_ = replaced.Close()
+
+	// failed reload closes the replacement while the original is kept
+	orig, err := NewKeba("127.0.0.1", "1002", RFID{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orig.Close()
+
+	unused, err := NewKeba("127.0.0.1", "1002", RFID{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = unused.Close()
+
+	if !kebaReport(t, orig, "1002") {
+		t.Error("original charger not receiving reports")
+	}
+}
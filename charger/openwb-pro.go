@@ -27,6 +27,7 @@ type OpenWBPro struct {
 	status  pro.Status
 	updated time.Time
 	cache   time.Duration
+	done    chan struct{}
 }
 
 // NewOpenWBProFromConfig creates a OpenWBPro charger from generic config
@@ -52,6 +53,7 @@ func NewOpenWBPro(uri string, cache time.Duration) (*OpenWBPro, error) {
 		uri:     strings.TrimRight(uri, "/"),
 		current: 6, // 6A defined value
 		cache:   cache,
+		done:    make(chan struct{}),
 	}
 
 	go wb.hearbeat(log)
@@ -60,13 +62,27 @@ func NewOpenWBPro(uri string, cache time.Duration) (*OpenWBPro, error) {
 }
 
 func (wb *OpenWBPro) hearbeat(log *util.Logger) {
-	for range time.NewTicker(30 * time.Second).C {
-		if _, err := wb.get(); err != nil {
-			log.ERROR.Printf("heartbeat: %v", err)
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wb.done:
+			return
+		case <-ticker.C:
+			if _, err := wb.get(); err != nil {
+				log.ERROR.Printf("heartbeat: %v", err)
+			}
 		}
 	}
 }
 
+// Close stops the heartbeat
+func (wb *OpenWBPro) Close() error {
+	close(wb.done)
+	return nil
+}
+
 func (wb *OpenWBPro) get() (pro.Status, error) {
 	wb.mu.Lock()
 	defer wb.mu.Unlock()
@@ -25,6 +25,7 @@ type OpenWB struct {
 	totalEnergyG  func() (float64, error)
 	currentsG     []func() (float64, error)
 	authS         func(string) error
+	done          chan struct{}
 }
 
 // go:generate go run ../cmd/tools/decorate.go -f decorateOpenWB -b *OpenWB -r api.Charger -t "api.ChargePhases,Phases1p3p,func(int) (error)" -t "api.Battery,SoC,func() (float64, error)"
@@ -116,6 +117,7 @@ func NewOpenWB(log *util.Logger, mqttconf mqtt.Config, id int, topic string, p1p
 		totalEnergyG:  totalEnergyG,
 		currentsG:     currentsG,
 		authS:         authS,
+		done:          make(chan struct{}),
 	}
 
 	// heartbeat
@@ -123,9 +125,17 @@ func NewOpenWB(log *util.Logger, mqttconf mqtt.Config, id int, topic string, p1p
 		heartbeatS := provider.NewMqtt(log, client, fmt.Sprintf("%s/set/isss/%s", topic, openwb.SlaveHeartbeatTopic),
 			timeout).WithRetained().IntSetter("heartbeat")
 
-		for range time.NewTicker(openwb.HeartbeatInterval).C {
-			if err := heartbeatS(1); err != nil {
-				log.ERROR.Printf("heartbeat: %v", err)
+		ticker := time.NewTicker(openwb.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				if err := heartbeatS(1); err != nil {
+					log.ERROR.Printf("heartbeat: %v", err)
+				}
 			}
 		}
 	}()
@@ -222,3 +232,9 @@ var _ api.Authorizer = (*OpenWB)(nil)
 func (m *OpenWB) Authorize(key string) error {
 	return m.authS(key)
 }
+
+// Close stops the heartbeat
+func (m *OpenWB) Close() error {
+	close(m.done)
+	return nil
+}
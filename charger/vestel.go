@@ -52,6 +52,7 @@ type Vestel struct {
 	log     *util.Logger
 	conn    *modbus.Connection
 	current uint16
+	done    chan struct{}
 }
 
 func init() {
@@ -89,6 +90,7 @@ func NewVestel(uri string, id uint8) (*Vestel, error) {
 		log:     log,
 		conn:    conn,
 		current: 6,
+		done:    make(chan struct{}),
 	}
 
 	// 5min failsafe timeout
@@ -103,13 +105,27 @@ func NewVestel(uri string, id uint8) (*Vestel, error) {
 
 // heartbeat implements the api.ChargerEx interface
 func (wb *Vestel) heartbeat() {
-	for range time.NewTicker(time.Minute).C {
-		if _, err := wb.conn.WriteSingleRegister(vestelRegAlive, 1); err != nil {
-			wb.log.ERROR.Println("heartbeat:", err)
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wb.done:
+			return
+		case <-ticker.C:
+			if _, err := wb.conn.WriteSingleRegister(vestelRegAlive, 1); err != nil {
+				wb.log.ERROR.Println("heartbeat:", err)
+			}
 		}
 	}
 }
 
+// Close stops the heartbeat
+func (wb *Vestel) Close() error {
+	close(wb.done)
+	return nil
+}
+
 // Status implements the api.Charger interface
 func (wb *Vestel) Status() (api.ChargeStatus, error) {
 	res := api.StatusA
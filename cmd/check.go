@@ -0,0 +1,495 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/charger"
+	"github.com/evcc-io/evcc/core"
+	"github.com/evcc-io/evcc/meter"
+	"github.com/evcc-io/evcc/push"
+	"github.com/evcc-io/evcc/server"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/vehicle"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/text/currency"
+	"gopkg.in/yaml.v3"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration tools",
+}
+
+// checkCmd represents the config check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate configuration",
+	Long: `Validate configuration without contacting any devices.
+Use --probe to additionally create all devices, tariffs and messengers and read their values.`,
+	Run: runConfigCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(checkCmd)
+	checkCmd.Flags().Bool("probe", false, "Create devices and read their values")
+}
+
+// minimum recommended vehicle poll interval
+const checkPollInterval = 60 * time.Minute
+
+// diagnostic is a single configuration problem
+type diagnostic struct {
+	Line    int
+	Path    string
+	Message string
+	Warning bool
+}
+
+func (d diagnostic) String() string {
+	severity := "error"
+	if d.Warning {
+		severity = "warning"
+	}
+
+	return fmt.Sprintf("%d: %s: %s: %s", d.Line, severity, d.Path, d.Message)
+}
+
+// configCheck collects configuration problems and resolves their yaml line numbers
+type configCheck struct {
+	root  *yaml.Node
+	diags []diagnostic
+}
+
+func newConfigCheck(data []byte) (*configCheck, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	return &configCheck{root: &root}, nil
+}
+
+// line returns the line of the deepest yaml node found along the dotted path
+func (c *configCheck) line(path string) int {
+	node := c.root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := node.Line
+
+	for _, segment := range strings.Split(path, ".") {
+		var next *yaml.Node
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if strings.EqualFold(node.Content[i].Value, segment) {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+
+		case yaml.SequenceNode:
+			if id, err := strconv.Atoi(segment); err == nil && id < len(node.Content) {
+				next = node.Content[id]
+				line = next.Line
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		node = next
+	}
+
+	return line
+}
+
+func (c *configCheck) add(warning bool, path, format string, a ...interface{}) {
+	c.diags = append(c.diags, diagnostic{
+		Line:    c.line(path),
+		Path:    path,
+		Message: fmt.Sprintf(format, a...),
+		Warning: warning,
+	})
+}
+
+func (c *configCheck) errorf(path, format string, a ...interface{}) {
+	c.add(false, path, format, a...)
+}
+
+func (c *configCheck) warnf(path, format string, a ...interface{}) {
+	c.add(true, path, format, a...)
+}
+
+// Errors returns the number of errors
+func (c *configCheck) Errors() (res int) {
+	for _, d := range c.diags {
+		if !d.Warning {
+			res++
+		}
+	}
+	return res
+}
+
+var invalidKeysRE = regexp.MustCompile(`^'(.*)' has invalid keys: (.*)$`)
+
+// decodeError splits decoder errors into individual diagnostics below path
+func (c *configCheck) decodeError(path string, err error) {
+	join := func(elem ...string) string {
+		var res []string
+		for _, e := range elem {
+			if e != "" {
+				res = append(res, e)
+			}
+		}
+		return strings.Join(res, ".")
+	}
+
+	var merr *mapstructure.Error
+	if !errors.As(err, &merr) {
+		c.errorf(path, "%v", err)
+		return
+	}
+
+	for _, e := range merr.Errors {
+		if m := invalidKeysRE.FindStringSubmatch(e); m != nil {
+			for _, key := range strings.Split(m[2], ", ") {
+				c.errorf(join(path, m[1], key), "unknown key")
+			}
+			continue
+		}
+
+		c.errorf(path, "%s", e)
+	}
+}
+
+// unmarshal decodes the configuration reporting unknown keys
+func (c *configCheck) unmarshal(v *viper.Viper) (conf config, err error) {
+	if err := v.UnmarshalExact(&conf); err != nil {
+		c.decodeError("", err)
+
+		// continue with all valid keys
+		conf = config{}
+		err = v.Unmarshal(&conf)
+	}
+
+	return conf, err
+}
+
+// devices validates device definitions and returns the set of defined names
+func (c *configCheck) devices(class string, cc []qualifiedConfig, registered func(string) bool) map[string]bool {
+	res := make(map[string]bool)
+
+	for id, cc := range cc {
+		path := fmt.Sprintf("%s.%d", class, id)
+
+		if cc.Name == "" {
+			c.errorf(path, "missing name")
+		} else if res[cc.Name] {
+			c.errorf(path+".name", "duplicate name: %s", cc.Name)
+		}
+		res[cc.Name] = true
+
+		if cc.Type == "" {
+			c.errorf(path, "missing type")
+		} else if !registered(cc.Type) {
+			c.errorf(path+".type", "invalid type: %s", cc.Type)
+		}
+	}
+
+	return res
+}
+
+// check validates the configuration without creating any devices
+func (c *configCheck) check(conf config) {
+	meters := c.devices("meters", conf.Meters, meter.Registered)
+	chargers := c.devices("chargers", conf.Chargers, charger.Registered)
+	vehicles := c.devices("vehicles", conf.Vehicles, vehicle.Registered)
+
+	// meters must only be used once https://github.com/evcc-io/evcc/issues/1744
+	used := make(map[string]string)
+
+	meterRef := func(path, ref string) {
+		if !meters[ref] {
+			c.errorf(path, "unknown meter: %s", ref)
+		} else if other, ok := used[ref]; ok {
+			c.errorf(path, "meter %s already used by %s", ref, other)
+		}
+		used[ref] = path
+	}
+
+	vehicleRef := func(path, ref string) {
+		if !vehicles[ref] {
+			c.errorf(path, "unknown vehicle: %s", ref)
+		}
+	}
+
+	c.site(conf.Site, meterRef)
+
+	if len(conf.LoadPoints) == 0 {
+		c.errorf("loadpoints", "missing loadpoints")
+	}
+
+	for id, other := range conf.LoadPoints {
+		path := fmt.Sprintf("loadpoints.%d", id)
+
+		lp := core.NewLoadPoint(util.NewLogger("lp-" + strconv.Itoa(id+1)))
+		if err := util.DecodeOther(other, lp); err != nil {
+			c.decodeError(path, err)
+			continue
+		}
+
+		switch {
+		case lp.ChargerRef == "":
+			c.errorf(path, "missing charger")
+		case !chargers[lp.ChargerRef]:
+			c.errorf(path+".charger", "unknown charger: %s", lp.ChargerRef)
+		}
+
+		if lp.MeterRef != "" {
+			meterRef(path+".meter", lp.MeterRef)
+		}
+
+		if lp.Meters.ChargeMeterRef != "" {
+			c.warnf(path+".meters.charge", "deprecated, use meter instead")
+			if lp.MeterRef != "" {
+				c.errorf(path+".meters.charge", "must not have meter and meters.charge both")
+			} else {
+				meterRef(path+".meters.charge", lp.Meters.ChargeMeterRef)
+			}
+		}
+
+		if lp.VehicleRef != "" {
+			vehicleRef(path+".vehicle", lp.VehicleRef)
+		}
+
+		for i, ref := range lp.VehiclesRef {
+			vehicleRef(fmt.Sprintf("%s.vehicles.%d", path, i), ref)
+		}
+
+		c.loadpoint(path, lp)
+	}
+
+	if conf.VehiclePoll.Interval < 0 {
+		c.errorf("vehiclePoll.interval", "must not be negative")
+	}
+
+	for account, limit := range conf.VehiclePoll.Limits {
+		if limit < 0 {
+			c.errorf("vehiclePoll.limits."+account, "must not be negative")
+		}
+	}
+
+	if conf.Tariffs.Currency != "" {
+		if _, err := currency.ParseISO(conf.Tariffs.Currency); err != nil {
+			c.errorf("tariffs.currency", "invalid currency: %s", conf.Tariffs.Currency)
+		}
+	}
+
+	// templates are validated without creating messengers
+	if _, err := push.NewHub(conf.Messaging.Events, nil); err != nil {
+		c.errorf("messaging.events", "%v", err)
+	}
+
+	if _, err := push.NewMonitor(conf.Messaging.Thresholds, conf.Messaging.Report, nil); err != nil {
+		c.errorf("messaging", "%v", err)
+	}
+
+	for id, service := range conf.Messaging.Services {
+		if service.Type == "" {
+			c.errorf(fmt.Sprintf("messaging.services.%d", id), "missing type")
+		}
+
+		if _, err := push.NewRouter(nil, service.Route, service.Quiet); err != nil {
+			c.errorf(fmt.Sprintf("messaging.services.%d", id), "%v", err)
+		}
+	}
+}
+
+// site validates the site's meter references
+func (c *configCheck) site(other map[string]interface{}, meterRef func(path, ref string)) {
+	site := core.NewSite()
+	if err := util.DecodeOther(other, site); err != nil {
+		c.decodeError("site", err)
+		return
+	}
+
+	if site.Meters.GridMeterRef != "" {
+		meterRef("site.meters.grid", site.Meters.GridMeterRef)
+	}
+
+	if site.Meters.PVMeterRef != "" && len(site.Meters.PVMetersRef) > 0 {
+		c.errorf("site.meters.pv", "cannot have pv and pvs both")
+	}
+
+	if site.Meters.PVMeterRef != "" {
+		meterRef("site.meters.pv", site.Meters.PVMeterRef)
+	}
+
+	for id, ref := range site.Meters.PVMetersRef {
+		meterRef(fmt.Sprintf("site.meters.pvs.%d", id), ref)
+	}
+
+	if site.Meters.BatteryMeterRef != "" && len(site.Meters.BatteryMetersRef) > 0 {
+		c.errorf("site.meters.battery", "cannot have battery and batteries both")
+	}
+
+	if site.Meters.BatteryMeterRef != "" {
+		meterRef("site.meters.battery", site.Meters.BatteryMeterRef)
+	}
+
+	for id, ref := range site.Meters.BatteryMetersRef {
+		meterRef(fmt.Sprintf("site.meters.batteries.%d", id), ref)
+	}
+
+	if site.Meters.GridMeterRef == "" && site.Meters.PVMeterRef == "" && len(site.Meters.PVMetersRef) == 0 {
+		c.errorf("site.meters", "missing either grid or pv meter")
+	}
+}
+
+// loadpoint validates the loadpoint's value ranges
+func (c *configCheck) loadpoint(path string, lp *core.LoadPoint) {
+	if lp.MinCurrent <= 0 {
+		c.errorf(path+".minCurrent", "must be positive")
+	}
+
+	if lp.MaxCurrent <= lp.MinCurrent {
+		c.errorf(path+".maxCurrent", "must be larger than minCurrent")
+	}
+
+	switch lp.Phases {
+	case 0, 1, 3:
+	default:
+		c.errorf(path+".phases", "must be 1 or 3")
+	}
+
+	if lp.SoC.Min < 0 || lp.SoC.Min > 100 {
+		c.errorf(path+".soc.min", "must be between 0 and 100")
+	}
+
+	if lp.SoC.Target < 0 || lp.SoC.Target > 100 {
+		c.errorf(path+".soc.target", "must be between 0 and 100")
+	}
+
+	if lp.SoC.Min > lp.SoC.Target {
+		c.warnf(path+".soc.min", "larger than target soc")
+	}
+
+	switch strings.ToLower(lp.SoC.Poll.Mode) {
+	case "", "charging", "connected", "always":
+	default:
+		c.errorf(path+".soc.poll.mode", "invalid poll mode: %s", lp.SoC.Poll.Mode)
+	}
+
+	if interval := lp.SoC.Poll.Interval; interval < 0 {
+		c.errorf(path+".soc.poll.interval", "must not be negative")
+	} else if interval > 0 && interval < checkPollInterval {
+		c.warnf(path+".soc.poll.interval", "lower than %v may deplete your battery or lead to API misuse", checkPollInterval)
+	}
+}
+
+// probe creates all devices, tariffs and messengers and reads their values
+func (c *configCheck) probe(conf config) {
+	if err := configureEnvironment(conf); err != nil {
+		c.errorf("", "%v", err)
+		return
+	}
+
+	for id, cc := range conf.Meters {
+		path := fmt.Sprintf("meters.%d", id)
+		if m, err := meter.NewFromConfig(cc.Type, cc.Other); err != nil {
+			c.errorf(path, "%v", err)
+		} else if _, err := m.CurrentPower(); err != nil {
+			c.errorf(path, "power: %v", err)
+		}
+	}
+
+	for id, cc := range conf.Chargers {
+		path := fmt.Sprintf("chargers.%d", id)
+		if ch, err := charger.NewFromConfig(cc.Type, cc.Other); err != nil {
+			c.errorf(path, "%v", err)
+		} else if _, err := ch.Status(); err != nil {
+			c.errorf(path, "status: %v", err)
+		}
+	}
+
+	for id, cc := range conf.Vehicles {
+		path := fmt.Sprintf("vehicles.%d", id)
+		if v, err := vehicle.NewFromConfig(cc.Type, cc.Other); err != nil {
+			c.errorf(path, "%v", err)
+		} else if _, err := v.SoC(); err != nil && !errors.Is(err, api.ErrMustRetry) {
+			c.errorf(path, "soc: %v", err)
+		}
+	}
+
+	if _, err := configureTariffs(conf.Tariffs); err != nil {
+		c.errorf("tariffs", "%v", err)
+	}
+
+	for id, service := range conf.Messaging.Services {
+		if _, err := push.NewMessengerFromConfig(service.Type, service.Other); err != nil {
+			c.errorf(fmt.Sprintf("messaging.services.%d", id), "%v", err)
+		}
+	}
+}
+
+func runConfigCheck(cmd *cobra.Command, args []string) {
+	util.LogLevel(viper.GetString("log"), viper.GetStringMapString("levels"))
+	log.INFO.Printf("evcc %s", server.FormattedVersion())
+
+	if cfgFile == "" {
+		log.FATAL.Fatal("missing evcc config")
+	}
+
+	data, err := os.ReadFile(cfgFile)
+	if err != nil {
+		log.FATAL.Fatal(err)
+	}
+
+	c, err := newConfigCheck(data)
+	if err != nil {
+		fmt.Printf("%s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+
+	conf, err := c.unmarshal(viper.GetViper())
+	if err == nil {
+		err = mergeManagedConfig(&conf)
+	}
+
+	if err != nil {
+		c.errorf("", "%v", err)
+	} else {
+		c.check(conf)
+
+		if probe, _ := cmd.Flags().GetBool("probe"); probe && c.Errors() == 0 {
+			c.probe(conf)
+		}
+	}
+
+	for _, d := range c.diags {
+		fmt.Printf("%s:%v\n", cfgFile, d)
+	}
+
+	if n := c.Errors(); n > 0 {
+		fmt.Printf("%d error(s), %d warning(s)\n", n, len(c.diags)-n)
+		os.Exit(1)
+	}
+
+	fmt.Printf("config ok, %d warning(s)\n", len(c.diags))
+}
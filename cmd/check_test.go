@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const checkSample = `
+meters:
+- name: grid
+  type: custom
+- name: grid
+  type: foo
+chargers:
+- name: wallbe
+  type: custom
+site:
+  meters:
+    grid: grid
+    pv: pv
+loadpoints:
+- charger: wallbox
+  meter: grid
+  minCurrent: 16
+  maxCurrent: 6
+  soc:
+    target: 120
+    poll:
+      interval: 1m
+- charger: wallbe
+  foo: bar
+unknown: true
+`
+
+func TestConfigCheck(t *testing.T) {
+	c, err := newConfigCheck([]byte(checkSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(checkSample)); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := c.unmarshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.check(conf)
+
+	expected := []diagnostic{
+		{Line: 25, Path: "unknown", Message: "unknown key"},
+		{Line: 5, Path: "meters.1.name", Message: "duplicate name: grid"},
+		{Line: 6, Path: "meters.1.type", Message: "invalid type: foo"},
+		{Line: 13, Path: "site.meters.pv", Message: "unknown meter: pv"},
+		{Line: 15, Path: "loadpoints.0.charger", Message: "unknown charger: wallbox"},
+		{Line: 16, Path: "loadpoints.0.meter", Message: "meter grid already used by site.meters.grid"},
+		{Line: 18, Path: "loadpoints.0.maxCurrent", Message: "must be larger than minCurrent"},
+		{Line: 20, Path: "loadpoints.0.soc.target", Message: "must be between 0 and 100"},
+		{Line: 22, Path: "loadpoints.0.soc.poll.interval", Message: "lower than 1h0m0s may deplete your battery or lead to API misuse", Warning: true},
+		{Line: 24, Path: "loadpoints.1.foo", Message: "unknown key"},
+	}
+
+	if len(c.diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(c.diags), c.diags)
+	}
+
+	for i, d := range c.diags {
+		if d != expected[i] {
+			t.Errorf("%d: expected %v, got %v", i, expected[i], d)
+		}
+	}
+
+	if c.Errors() != len(expected)-1 {
+		t.Errorf("expected %d errors, got %d", len(expected)-1, c.Errors())
+	}
+}
@@ -3,11 +3,14 @@ package cmd
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/evcc-io/evcc/api"
 	"github.com/evcc-io/evcc/charger"
+	"github.com/evcc-io/evcc/core/devices"
+	"github.com/evcc-io/evcc/core/poller"
 	"github.com/evcc-io/evcc/meter"
 	"github.com/evcc-io/evcc/provider/mqtt"
 	"github.com/evcc-io/evcc/push"
@@ -36,6 +39,7 @@ type config struct {
 	Meters       []qualifiedConfig
 	Chargers     []qualifiedConfig
 	Vehicles     []qualifiedConfig
+	VehiclePoll  vehiclePollConfig
 	Tariffs      tariffConfig
 	Site         map[string]interface{}
 	LoadPoints   []map[string]interface{}
@@ -64,14 +68,30 @@ type typedConfig struct {
 }
 
 type messagingConfig struct {
-	Events   map[string]push.EventTemplateConfig
-	Services []typedConfig
+	Events     map[string]push.EventTemplateConfig
+	Services   []messengerConfig
+	Thresholds []push.ThresholdConfig
+	Report     push.ReportConfig
+}
+
+// messengerConfig is a messenger with its optional routing and quiet hours
+type messengerConfig struct {
+	Type  string
+	Route push.RouteConfig
+	Quiet push.QuietConfig
+	Other map[string]interface{} `mapstructure:",remain"`
+}
+
+type vehiclePollConfig struct {
+	Interval time.Duration            // minimum interval between requests per account
+	Limits   map[string]time.Duration // per brand or brand/user account intervals
 }
 
 type tariffConfig struct {
 	Currency string
 	Grid     typedConfig
 	FeedIn   typedConfig
+	CO2      typedConfig
 }
 
 // ConfigProvider provides configuration items
@@ -150,6 +170,7 @@ func (cp *ConfigProvider) configureMeters(conf config) error {
 			return fmt.Errorf("duplicate meter name: %s already defined and must be unique", cc.Name)
 		}
 
+		devices.Instance.Register(devices.Meter, cc.Name, m)
 		cp.meters[cc.Name] = m
 	}
 
@@ -173,6 +194,7 @@ func (cp *ConfigProvider) configureChargers(conf config) error {
 			return fmt.Errorf("duplicate charger name: %s already defined and must be unique", cc.Name)
 		}
 
+		devices.Instance.Register(devices.Charger, cc.Name, c)
 		cp.chargers[cc.Name] = c
 	}
 
@@ -180,6 +202,8 @@ func (cp *ConfigProvider) configureChargers(conf config) error {
 }
 
 func (cp *ConfigProvider) configureVehicles(conf config) error {
+	poller.Instance.Configure(conf.VehiclePoll.Interval, conf.VehiclePoll.Limits)
+
 	cp.vehicles = make(map[string]api.Vehicle)
 	for id, cc := range conf.Vehicles {
 		if cc.Name == "" {
@@ -196,12 +220,31 @@ func (cp *ConfigProvider) configureVehicles(conf config) error {
 			return fmt.Errorf("duplicate vehicle name: %s already defined and must be unique", cc.Name)
 		}
 
+		// share api rate limits between vehicles of same account
+		poller.Instance.Register(v, vehicleAccount(cc))
+
+		devices.Instance.Register(devices.Vehicle, cc.Name, v)
 		cp.vehicles[cc.Name] = v
 	}
 
 	return nil
 }
 
+// vehicleAccount returns the vehicle's api account in brand/user notation
+func vehicleAccount(cc qualifiedConfig) string {
+	brand := strings.ToLower(cc.Type)
+	if tmpl, ok := cc.Other["template"]; ok && brand == "template" {
+		brand = strings.ToLower(fmt.Sprint(tmpl))
+	}
+
+	if user, ok := cc.Other["user"]; ok {
+		return fmt.Sprintf("%s/%v", brand, user)
+	}
+
+	// no shared account
+	return fmt.Sprintf("%s/%s", brand, cc.Name)
+}
+
 // webControl handles routing for devices. For now only api.ProviderLogin related routes
 func (cp *ConfigProvider) webControl(httpd *server.HTTPd, paramC chan<- util.Param) {
 	router := httpd.Router()
@@ -0,0 +1,92 @@
+package configure
+
+import (
+	"fmt"
+
+	"github.com/evcc-io/evcc/detect"
+	"github.com/evcc-io/evcc/util/templates"
+)
+
+// detectDevices scans the local network for devices that can be selected during configuration
+func (c *CmdConfigure) detectDevices() {
+	fmt.Println()
+	fmt.Println(c.localizedString("Detect_Running", nil))
+
+	hosts, err := detect.Hosts(c.log, nil)
+	if err != nil {
+		fmt.Println("  ", c.localizedString("Error", localizeMap{"Error": err}))
+		return
+	}
+
+	c.suggestions = detect.Suggest(detect.Work(c.log, 50, hosts))
+
+	fmt.Println()
+	fmt.Println(c.localizedString("Detect_Found", localizeMap{"Count": len(c.suggestions)}))
+}
+
+// categorySuggestions returns the detected devices matching the device category
+func (c *CmdConfigure) categorySuggestions(deviceCategory DeviceCategory) []detect.Suggestion {
+	class := DeviceCategories[deviceCategory].class.String()
+	usage := DeviceCategories[deviceCategory].categoryFilter.String()
+
+	var res []detect.Suggestion
+	for _, s := range c.suggestions {
+		if s.Class == class && (usage == "" || s.Usage == usage) {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+// selectSuggestion lets the user select a detected device of the device category.
+// The detected values are used as parameter defaults of the selected template.
+func (c *CmdConfigure) selectSuggestion(deviceCategory DeviceCategory) (templates.Template, bool) {
+	suggestions := c.categorySuggestions(deviceCategory)
+	if len(suggestions) == 0 {
+		return templates.Template{}, false
+	}
+
+	var items []templates.Template
+	var kept []detect.Suggestion
+	var titles []string
+
+	// suggestions without template are skipped
+	for _, s := range suggestions {
+		tmpl, err := templates.ByTemplate(s.Template, s.Class)
+		if err != nil {
+			continue
+		}
+
+		tmpl.Lang = c.lang
+		tmpl.SetCombinedTitle()
+
+		items = append(items, tmpl)
+		kept = append(kept, s)
+		titles = append(titles, fmt.Sprintf("%s (%s)", tmpl.Title(), s.IP))
+	}
+
+	titles = append(titles, c.localizedString("Detect_Other", nil))
+
+	fmt.Println()
+	index, _ := c.askChoice(c.localizedString("Detect_Select", localizeMap{"Category": DeviceCategories[deviceCategory].title}), titles)
+	if index >= len(items) {
+		return templates.Template{}, false
+	}
+
+	c.prefill = kept[index].Values
+
+	return items[index], true
+}
+
+// applyPrefill sets the detected values as parameter defaults
+func (c *CmdConfigure) applyPrefill(templateItem *templates.Template) {
+	for name, value := range c.prefill {
+		if name == templates.ParamUsage || name == templates.ParamModbus {
+			continue
+		}
+		templateItem.SetParamDefault(name, fmt.Sprint(value))
+	}
+
+	c.prefill = nil
+}
@@ -2,6 +2,7 @@ package configure
 
 import (
 	"errors"
+	"io"
 	"time"
 
 	"github.com/evcc-io/evcc/api"
@@ -31,6 +32,12 @@ type DeviceTest struct {
 // - error: != nil if the device is invalid and can not be configured with the provided settings
 func (d *DeviceTest) Test() (DeviceTestResult, error) {
 	v, err := d.configure()
+
+	// release device created for testing only
+	if c, ok := v.(io.Closer); ok {
+		defer c.Close()
+	}
+
 	if err != nil {
 		return DeviceTestResultInvalid, err
 	}
@@ -17,7 +17,10 @@ import (
 
 // processDeviceSelection processes the the user selected device, check if it is an actual device and make sure the requirements are set
 func (c *CmdConfigure) processDeviceSelection(deviceCategory DeviceCategory) (templates.Template, error) {
-	templateItem := c.selectItem(deviceCategory)
+	templateItem, ok := c.selectSuggestion(deviceCategory)
+	if !ok {
+		templateItem = c.selectItem(deviceCategory)
+	}
 
 	if templateItem.Title() == c.localizedString("ItemNotPresent", nil) {
 		return templateItem, c.errItemNotPresent
@@ -411,6 +414,7 @@ func (c *CmdConfigure) processConfig(templateItem *templates.Template, deviceCat
 	fmt.Println()
 
 	c.processModbusConfig(templateItem, deviceCategory)
+	c.applyPrefill(templateItem)
 
 	return c.processParams(templateItem, deviceCategory)
 }
@@ -584,9 +588,11 @@ func (c *CmdConfigure) processModbusConfig(templateItem *templates.Template, dev
 		return
 	}
 
-	// ask for modbus interface type
+	// ask for modbus interface type unless detected
 	var index int
-	if len(choices) > 1 {
+	if detected := funk.IndexOfString(choiceTypes, fmt.Sprint(c.prefill[templates.ParamModbus])); detected >= 0 {
+		index = detected
+	} else if len(choices) > 1 {
 		index, _ = c.askChoice(c.localizedString("Config_ModbusInterface", nil), choices)
 	}
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/cloudfoundry/jibber_jabber"
+	"github.com/evcc-io/evcc/detect"
 	"github.com/evcc-io/evcc/hems/semp"
 	"github.com/evcc-io/evcc/server"
 	"github.com/evcc-io/evcc/util"
@@ -37,6 +38,9 @@ type CmdConfigure struct {
 	errItemNotPresent, errDeviceNotValid error
 
 	capabilitySMAHems bool
+
+	suggestions []detect.Suggestion    // detected devices
+	prefill     map[string]interface{} // detected values of the selected device
 }
 
 // Run starts the interactive configuration
@@ -72,6 +76,11 @@ func (c *CmdConfigure) Run(log *util.Logger, flagLang string, advancedMode, expa
 	fmt.Println()
 	fmt.Println(c.localizedString("Intro", nil))
 
+	fmt.Println()
+	if c.askYesNo(c.localizedString("Detect_Question", nil)) {
+		c.detectDevices()
+	}
+
 	if !c.advancedMode {
 		// ask the user for his knowledge, so advanced mode can also be turned on this way
 		fmt.Println()
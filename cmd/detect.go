@@ -10,7 +10,6 @@ import (
 	"github.com/evcc-io/evcc/detect"
 	"github.com/evcc-io/evcc/detect/tasks"
 	"github.com/evcc-io/evcc/util"
-	"github.com/korylprince/ipnetgen"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -32,42 +31,6 @@ func init() {
 	rootCmd.AddCommand(detectCmd)
 }
 
-// IPsFromSubnet creates a list of ip addresses for given subnet
-func IPsFromSubnet(arg string) (res []string) {
-	gen, err := ipnetgen.New(arg)
-	if err != nil {
-		log.FATAL.Fatal("could not create iterator")
-	}
-
-	for ip := gen.Next(); ip != nil; ip = gen.Next() {
-		res = append(res, ip.String())
-	}
-	// remove network and broadcast address
-	return res[1 : len(res)-1]
-}
-
-// ParseHostIPNet converts host or cidr into a host list
-func ParseHostIPNet(arg string) (res []string) {
-	if ip := net.ParseIP(arg); ip != nil {
-		return []string{ip.String()}
-	}
-
-	_, ipnet, err := net.ParseCIDR(arg)
-
-	// simple host
-	if err != nil {
-		return []string{arg}
-	}
-
-	// check subnet size
-	if bits, _ := ipnet.Mask.Size(); bits < 24 {
-		log.INFO.Println("skipping large subnet:", ipnet)
-		return
-	}
-
-	return IPsFromSubnet(arg)
-}
-
 func display(res []tasks.Result) {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader([]string{"IP", "Hostname", "Task", "Details"})
@@ -97,6 +60,24 @@ func display(res []tasks.Result) {
 	table.Render()
 }
 
+func displaySuggestions(res []detect.Suggestion) {
+	if len(res) == 0 {
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"IP", "Class", "Template", "Usage"})
+	table.SetAutoMergeCells(true)
+	table.SetRowLine(true)
+
+	for _, s := range res {
+		table.Append([]string{s.IP, s.Class, s.Template, s.Usage})
+	}
+
+	fmt.Println("")
+	table.Render()
+}
+
 func runDetect(cmd *cobra.Command, args []string) {
 	util.LogLevel(viper.GetString("log"), nil)
 
@@ -107,27 +88,13 @@ On successful detection, suggestions for EVCC configuration can be made. The sug
 configuring EVCC but are probably not sufficient for fully automatic configuration.`)
 	fmt.Println()
 
-	// args
-	var hosts []string
-	for _, arg := range args {
-		hosts = append(hosts, ParseHostIPNet(arg)...)
-	}
-
-	// autodetect
-	if len(hosts) == 0 {
-		ips := util.LocalIPs()
-		if len(ips) == 0 {
-			log.FATAL.Fatal("could not find ip")
-		}
-
-		myIP := ips[0]
-		log.INFO.Println("my ip:", myIP.IP)
-
-		hosts = append(hosts, "127.0.0.1")
-		hosts = append(hosts, IPsFromSubnet(myIP.String())...)
+	hosts, err := detect.Hosts(log, args)
+	if err != nil {
+		log.FATAL.Fatal(err)
 	}
 
 	// magic happens here
 	res := detect.Work(log, 50, hosts)
 	display(res)
+	displaySuggestions(detect.Suggest(res))
 }
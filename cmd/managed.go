@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/evcc-io/evcc/cmd/configure"
+	"github.com/evcc-io/evcc/core"
+	"github.com/evcc-io/evcc/server"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// managedConfig contains the devices configured through the web ui
+type managedConfig struct {
+	Meters   []map[string]interface{} `yaml:"meters,omitempty"`
+	Chargers []map[string]interface{} `yaml:"chargers,omitempty"`
+	Vehicles []map[string]interface{} `yaml:"vehicles,omitempty"`
+}
+
+// managedConfigFile returns the managed device file located next to the config file
+func managedConfigFile() string {
+	if cfgFile == "" {
+		return ""
+	}
+	return strings.TrimSuffix(cfgFile, filepath.Ext(cfgFile)) + ".devices.yaml"
+}
+
+// readManagedConfig reads the managed device file. A missing file is not an error.
+func readManagedConfig() (res managedConfig, err error) {
+	file := managedConfigFile()
+	if file == "" {
+		return res, errors.New("missing evcc config")
+	}
+
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return res, nil
+	}
+
+	if err == nil {
+		err = yaml.Unmarshal(b, &res)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("failed reading managed config %s: %w", file, err)
+	}
+
+	return res, err
+}
+
+// writeManagedConfig replaces the managed device file
+func writeManagedConfig(mc managedConfig) error {
+	b, err := yaml.Marshal(mc)
+	if err != nil {
+		return err
+	}
+
+	file := managedConfigFile()
+	tmp := file + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
+
+// devices returns a pointer to the device list of the class
+func (mc *managedConfig) devices(class string) *[]map[string]interface{} {
+	switch class {
+	case templates.Meter:
+		return &mc.Meters
+	case templates.Charger:
+		return &mc.Chargers
+	default:
+		return &mc.Vehicles
+	}
+}
+
+// mergeManagedConfig appends the managed devices to the configuration
+func mergeManagedConfig(conf *config) error {
+	if cfgFile == "" {
+		return nil
+	}
+
+	mc, err := readManagedConfig()
+	if err != nil {
+		return err
+	}
+
+	merge := func(cc *[]qualifiedConfig, devices []map[string]interface{}) error {
+		for _, other := range devices {
+			var q qualifiedConfig
+			if err := util.DecodeOther(other, &q); err != nil {
+				return err
+			}
+			*cc = append(*cc, q)
+		}
+		return nil
+	}
+
+	if err := merge(&conf.Meters, mc.Meters); err != nil {
+		return err
+	}
+
+	if err := merge(&conf.Chargers, mc.Chargers); err != nil {
+		return err
+	}
+
+	return merge(&conf.Vehicles, mc.Vehicles)
+}
+
+// configManager implements the web ui device configuration using the managed device file
+type configManager struct {
+	mu       sync.Mutex
+	reloader *reloader
+}
+
+var _ server.ConfigManager = (*configManager)(nil)
+
+// deviceConfig converts the device configuration to its managed representation
+func deviceConfig(conf server.DeviceConfig) map[string]interface{} {
+	res := make(map[string]interface{}, len(conf.Values)+3)
+	for k, v := range conf.Values {
+		res[k] = v
+	}
+
+	res["name"] = conf.Name
+	res["type"] = "template"
+	res["template"] = conf.Template
+
+	return res
+}
+
+// maskedParams returns the names of the template's masked parameters like passwords
+func maskedParams(class, template string) map[string]bool {
+	res := make(map[string]bool)
+
+	if tmpl, err := templates.ByTemplate(template, class); err == nil {
+		for _, p := range tmpl.Params {
+			if p.Mask {
+				res[p.Name] = true
+			}
+		}
+	}
+
+	return res
+}
+
+// unmask replaces masked values sent back unchanged with the stored values
+func unmask(class string, conf server.DeviceConfig, stored map[string]interface{}) {
+	masked := maskedParams(class, conf.Template)
+
+	for k, v := range conf.Values {
+		if prev, ok := stored[k]; ok && masked[k] && v == server.MaskedValue {
+			conf.Values[k] = prev
+		}
+	}
+}
+
+// Test implements the server.ConfigManager interface
+func (m *configManager) Test(class string, conf server.DeviceConfig) (string, error) {
+	tmpl, err := templates.ByTemplate(conf.Template, class)
+	if err != nil {
+		return "", err
+	}
+
+	var category configure.DeviceCategory
+	switch class {
+	case templates.Charger:
+		category = configure.DeviceCategoryCharger
+	case templates.Meter:
+		category = configure.DeviceCategory(fmt.Sprint(conf.Values[templates.ParamUsage]))
+		if _, ok := configure.DeviceCategories[category]; !ok {
+			return "", fmt.Errorf("invalid meter usage: %v", conf.Values[templates.ParamUsage])
+		}
+	default:
+		category = configure.DeviceCategoryVehicle
+	}
+
+	dt := configure.DeviceTest{
+		DeviceCategory: category,
+		Template:       tmpl,
+		ConfigValues:   conf.Values,
+	}
+
+	res, err := dt.Test()
+
+	return string(res), err
+}
+
+// Devices implements the server.ConfigManager interface
+func (m *configManager) Devices(class string) ([]server.DeviceConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mc, err := readManagedConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]server.DeviceConfig, 0)
+	for _, other := range *mc.devices(class) {
+		masked := maskedParams(class, fmt.Sprint(other["template"]))
+
+		values := make(map[string]interface{})
+		for k, v := range other {
+			switch {
+			case k == "name" || k == "type" || k == "template":
+				continue
+			case masked[k] && fmt.Sprint(v) != "":
+				v = server.MaskedValue
+			}
+			values[k] = v
+		}
+
+		res = append(res, server.DeviceConfig{
+			Name:     fmt.Sprint(other["name"]),
+			Template: fmt.Sprint(other["template"]),
+			Values:   values,
+		})
+	}
+
+	return res, nil
+}
+
+// Add implements the server.ConfigManager interface
+func (m *configManager) Add(class string, conf server.DeviceConfig) (bool, error) {
+	return m.save(class, conf, true)
+}
+
+// Update implements the server.ConfigManager interface
+func (m *configManager) Update(class string, conf server.DeviceConfig) (bool, error) {
+	return m.save(class, conf, false)
+}
+
+// save adds a new or replaces an existing managed device
+func (m *configManager) save(class string, conf server.DeviceConfig, add bool) (bool, error) {
+	if conf.Name == "" {
+		return false, errors.New("missing name")
+	}
+
+	tmpl, err := templates.ByTemplate(conf.Template, class)
+	if err != nil {
+		return false, err
+	}
+
+	return m.update(class, conf.Name, func(devices []map[string]interface{}, id int) ([]map[string]interface{}, error) {
+		switch {
+		case add && (id >= 0 || m.reloader.defined(class, conf.Name)):
+			return nil, fmt.Errorf("duplicate %s name: %s already defined and must be unique", class, conf.Name)
+
+		case !add && id < 0:
+			return nil, fmt.Errorf("%s %s: %w", class, conf.Name, server.ErrDeviceNotFound)
+
+		case !add:
+			unmask(class, conf, devices[id])
+		}
+
+		// validate parameters without creating the device
+		if _, _, err := tmpl.RenderResult(templates.TemplateRenderModeInstance, conf.Values); err != nil {
+			return nil, err
+		}
+
+		if add {
+			return append(devices, deviceConfig(conf)), nil
+		}
+
+		devices[id] = deviceConfig(conf)
+		return devices, nil
+	})
+}
+
+// Delete implements the server.ConfigManager interface
+func (m *configManager) Delete(class, name string) (bool, error) {
+	return m.update(class, name, func(devices []map[string]interface{}, id int) ([]map[string]interface{}, error) {
+		if id < 0 {
+			return nil, fmt.Errorf("%s %s: %w", class, name, server.ErrDeviceNotFound)
+		}
+
+		if m.reloader.referenced(class, name) {
+			return nil, fmt.Errorf("%s %s is in use", class, name)
+		}
+
+		return append(devices[:id], devices[id+1:]...), nil
+	})
+}
+
+// update modifies the managed devices of the class, saves and reloads the configuration.
+// Returns true if the change has been saved but requires a restart to be applied.
+// Changes that fail to apply for other reasons are rolled back.
+func (m *configManager) update(class, name string, fun func([]map[string]interface{}, int) ([]map[string]interface{}, error)) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mc, err := readManagedConfig()
+	if err != nil {
+		return false, err
+	}
+
+	// separate copy for rollback since device lists are modified in place
+	prev, err := readManagedConfig()
+	if err != nil {
+		return false, err
+	}
+
+	devices := mc.devices(class)
+
+	id := -1
+	for i, other := range *devices {
+		if other["name"] == name {
+			id = i
+		}
+	}
+
+	res, err := fun(*devices, id)
+	if err != nil {
+		return false, err
+	}
+	*devices = res
+
+	if err := writeManagedConfig(mc); err != nil {
+		return false, err
+	}
+
+	if err := m.reloader.Reload(); err != nil {
+		if errors.Is(err, core.ErrRestartRequired) {
+			log.WARN.Printf("config: %v", err)
+			return true, nil
+		}
+
+		// restore previous configuration such that restart and later reloads succeed
+		if err := writeManagedConfig(prev); err != nil {
+			log.ERROR.Printf("config: restoring managed devices: %v", err)
+		}
+
+		return false, err
+	}
+
+	return false, nil
+}
+
+// defined returns true if a device of the class with given name exists in the running configuration
+func (r *reloader) defined(class, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cc := r.conf.Vehicles
+	switch class {
+	case templates.Meter:
+		cc = r.conf.Meters
+	case templates.Charger:
+		cc = r.conf.Chargers
+	}
+
+	for _, c := range cc {
+		if c.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// referenced returns true if the device is used by the site or any loadpoint of the running configuration
+func (r *reloader) referenced(class, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return referenced(r.conf, class, name)
+}
+
+// referenced returns true if the device is used by the site or any loadpoint of the configuration
+func referenced(conf config, class, name string) bool {
+	site := core.NewSite()
+	_ = util.DecodeOther(conf.Site, site)
+
+	if class == templates.Meter {
+		m := site.Meters
+		refs := append(append([]string{m.GridMeterRef, m.PVMeterRef, m.BatteryMeterRef}, m.PVMetersRef...), m.BatteryMetersRef...)
+		for _, ref := range refs {
+			if ref == name {
+				return true
+			}
+		}
+	}
+
+	for _, other := range conf.LoadPoints {
+		lp := core.NewLoadPoint(log)
+		_ = util.DecodeOther(other, lp)
+
+		var refs []string
+		switch class {
+		case templates.Meter:
+			refs = []string{lp.MeterRef, lp.Meters.ChargeMeterRef}
+		case templates.Charger:
+			refs = []string{lp.ChargerRef}
+		default:
+			refs = append([]string{lp.VehicleRef}, lp.VehiclesRef...)
+		}
+
+		for _, ref := range refs {
+			if ref == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
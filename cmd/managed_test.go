@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/evcc-io/evcc/server"
+	"github.com/evcc-io/evcc/util/templates"
+)
+
+func TestManagedConfig(t *testing.T) {
+	defer func(file string) { cfgFile = file }(cfgFile)
+	cfgFile = filepath.Join(t.TempDir(), "evcc.yaml")
+
+	if file := managedConfigFile(); filepath.Base(file) != "evcc.devices.yaml" {
+		t.Errorf("unexpected managed config file: %s", file)
+	}
+
+	// missing file
+	conf := config{Meters: []qualifiedConfig{{Name: "grid", Type: "custom"}}}
+	if err := mergeManagedConfig(&conf); err != nil || len(conf.Meters) != 1 {
+		t.Fatalf("unexpected merge result: %v %v", conf.Meters, err)
+	}
+
+	var mc managedConfig
+	devices := mc.devices(templates.Charger)
+	*devices = append(*devices, deviceConfig(server.DeviceConfig{
+		Name:     "wallbox",
+		Template: "demo",
+		Values:   map[string]interface{}{"host": "192.0.2.2"},
+	}))
+
+	if err := writeManagedConfig(mc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mergeManagedConfig(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conf.Meters) != 1 || len(conf.Chargers) != 1 {
+		t.Fatalf("unexpected devices: %v %v", conf.Meters, conf.Chargers)
+	}
+
+	cc := conf.Chargers[0]
+	if cc.Name != "wallbox" || cc.Type != "template" || cc.Other["template"] != "demo" || cc.Other["host"] != "192.0.2.2" {
+		t.Errorf("unexpected charger: %+v", cc)
+	}
+}
+
+func TestManagedConfigMasked(t *testing.T) {
+	defer func(file string) { cfgFile = file }(cfgFile)
+	cfgFile = filepath.Join(t.TempDir(), "evcc.yaml")
+
+	var mc managedConfig
+	devices := mc.devices(templates.Vehicle)
+	*devices = append(*devices, deviceConfig(server.DeviceConfig{
+		Name:     "car",
+		Template: "fiat",
+		Values:   map[string]interface{}{"user": "foo", "password": "secret", "pin": "1234"},
+	}))
+
+	if err := writeManagedConfig(mc); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := new(configManager).Devices(templates.Vehicle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 {
+		t.Fatalf("unexpected devices: %+v", res)
+	}
+
+	if v := res[0].Values; v["user"] != "foo" || v["password"] != server.MaskedValue || v["pin"] != server.MaskedValue {
+		t.Errorf("unexpected values: %+v", v)
+	}
+
+	// update keeps masked values sent back
+	conf := res[0]
+	conf.Values["user"] = "bar"
+	unmask(templates.Vehicle, conf, (*devices)[0])
+
+	if v := conf.Values; v["user"] != "bar" || v["password"] != "secret" || v["pin"] != "1234" {
+		t.Errorf("unexpected values: %+v", v)
+	}
+}
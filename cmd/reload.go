@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/charger"
+	"github.com/evcc-io/evcc/core"
+	"github.com/evcc-io/evcc/core/devices"
+	"github.com/evcc-io/evcc/core/poller"
+	"github.com/evcc-io/evcc/meter"
+	"github.com/evcc-io/evcc/push"
+	"github.com/evcc-io/evcc/tariff"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/templates"
+	"github.com/evcc-io/evcc/vehicle"
+	"github.com/spf13/viper"
+	"github.com/thoas/go-funk"
+)
+
+// reloader re-reads the config file and adds, replaces or removes changed devices, tariffs and messengers
+// of the running site and applies loadpoint mode, threshold and delay settings.
+// Changes to any other configuration require a restart.
+type reloader struct {
+	mu    sync.Mutex
+	conf  config
+	cp    *ConfigProvider
+	site  *core.Site
+	hub   *push.Hub
+	cache *util.Cache
+
+	retired []interface{} // replaced instances to be closed after reconfiguration
+}
+
+// Reload reloads the configuration file and applies all changes
+func (r *reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cfgFile == "" {
+		return errors.New("missing evcc config")
+	}
+
+	log.INFO.Println("reloading config file", cfgFile)
+
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed reading config file: %w", err)
+	}
+
+	var conf config
+	if err := viper.UnmarshalExact(&conf); err != nil {
+		return fmt.Errorf("failed parsing config file: %w", err)
+	}
+
+	if err := mergeManagedConfig(&conf); err != nil {
+		return err
+	}
+
+	if err := r.validate(conf); err != nil {
+		return err
+	}
+
+	// create all changed instances before touching the running site
+	meters, err := r.createMeters(conf.Meters)
+	if err != nil {
+		return err
+	}
+
+	chargers, err := r.createChargers(conf.Chargers)
+	if err != nil {
+		r.closeUnused(meters, nil, nil)
+		return err
+	}
+
+	vehicles, err := r.createVehicles(conf.Vehicles)
+	if err != nil {
+		r.closeUnused(meters, chargers, nil)
+		return err
+	}
+
+	settings := r.loadPointSettings(conf.LoadPoints)
+
+	var tariffs *tariff.Tariffs
+	if !reflect.DeepEqual(r.conf.Tariffs, conf.Tariffs) {
+		res, err := configureTariffs(conf.Tariffs)
+		if err != nil {
+			r.closeUnused(meters, chargers, vehicles)
+			return err
+		}
+		tariffs = &res
+	}
+
+	var hub *push.Hub
+	if !reflect.DeepEqual(r.conf.Messaging, conf.Messaging) {
+		if hub, err = createMessengers(conf.Messaging, conf.Mqtt.RootTopic(), r.cache); err != nil {
+			r.closeUnused(meters, chargers, vehicles)
+			if tariffs != nil {
+				tariffs.Close()
+			}
+			return err
+		}
+		hub.Control(r.site.LoadPoints())
+	}
+
+	// replaced and removed instances are closed once the site no longer uses them
+	r.retired = nil
+	defer func() {
+		for _, dev := range r.retired {
+			closeDevice(dev)
+		}
+	}()
+
+	// swap instances within the site's control loop
+	if err := r.site.Reconfigure(func() error {
+		if err := r.apply(meters, chargers, vehicles, tariffs); err != nil {
+			return err
+		}
+
+		r.remove(conf)
+
+		for id, cc := range settings {
+			if err := r.site.ReconfigureLoadPoint(id, cc); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		r.closeUnused(meters, chargers, vehicles)
+		if hub != nil {
+			hub.Stop()
+		}
+		return err
+	}
+
+	if hub != nil {
+		log.INFO.Println("messengers replaced")
+		r.hub.Replace(hub)
+	}
+
+	util.LogLevel(conf.Log, conf.Levels)
+
+	r.conf = conf
+
+	return nil
+}
+
+// loadPointReloadable are the lower case loadpoint config keys that can be changed without restart
+var loadPointReloadable = []string{"mode", "enable", "disable", "phaseswitch"}
+
+// validate ensures that only reloadable configuration has been changed
+func (r *reloader) validate(conf config) error {
+	// reloadable sections
+	reloadable := func(c config) config {
+		c.Log, c.Levels = "", nil
+		c.Tariffs = tariffConfig{}
+		c.Messaging = messagingConfig{}
+		c.Meters, c.Chargers, c.Vehicles = nil, nil, nil
+		c.LoadPoints = loadPointStatics(c.LoadPoints)
+		return c
+	}
+
+	if !reflect.DeepEqual(reloadable(r.conf), reloadable(conf)) {
+		return fmt.Errorf("configuration changes beyond devices, tariffs, messaging and loadpoint settings: %w", core.ErrRestartRequired)
+	}
+
+	for class, devices := range map[string][2][]qualifiedConfig{
+		templates.Meter:   {r.conf.Meters, conf.Meters},
+		templates.Charger: {r.conf.Chargers, conf.Chargers},
+		templates.Vehicle: {r.conf.Vehicles, conf.Vehicles},
+	} {
+		names := make(map[string]bool)
+		for id, cc := range devices[1] {
+			if cc.Name == "" {
+				return fmt.Errorf("cannot create %s %s: missing name", humanize.Ordinal(id+1), class)
+			}
+			if names[cc.Name] {
+				return fmt.Errorf("duplicate %s name: %s already defined and must be unique", class, cc.Name)
+			}
+			names[cc.Name] = true
+		}
+
+		// added devices cannot be referenced since site and loadpoints are unchanged
+		for _, name := range removed(devices[0], devices[1]) {
+			if referenced(conf, class, name) {
+				return fmt.Errorf("cannot remove %s '%s': in use", class, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadPointStatics removes the reloadable settings from the loadpoint configs
+func loadPointStatics(lps []map[string]interface{}) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(lps))
+	for _, lp := range lps {
+		static := make(map[string]interface{}, len(lp))
+		for k, v := range lp {
+			if !funk.ContainsString(loadPointReloadable, strings.ToLower(k)) {
+				static[k] = v
+			}
+		}
+		res = append(res, static)
+	}
+	return res
+}
+
+// loadPointSettings returns the settings of changed loadpoints by index
+func (r *reloader) loadPointSettings(lps []map[string]interface{}) map[int]core.LoadPointSettings {
+	res := make(map[int]core.LoadPointSettings)
+
+	for id, other := range lps {
+		if id < len(r.conf.LoadPoints) && reflect.DeepEqual(r.conf.LoadPoints[id], other) {
+			continue
+		}
+
+		// decode including defaults, config has been validated at startup
+		lp := core.NewLoadPoint(log)
+		_ = util.DecodeOther(other, lp)
+
+		res[id] = core.LoadPointSettings{
+			Mode:        lp.Mode,
+			Enable:      lp.Enable,
+			Disable:     lp.Disable,
+			PhaseSwitch: lp.PhaseSwitch,
+		}
+	}
+
+	return res
+}
+
+// find returns the config with given name
+func find(cc []qualifiedConfig, name string) (qualifiedConfig, bool) {
+	for _, c := range cc {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return qualifiedConfig{}, false
+}
+
+// changed returns the configs that have been added or differ from the current configuration
+func changed(current, cc []qualifiedConfig) []qualifiedConfig {
+	var res []qualifiedConfig
+	for _, c := range cc {
+		if old, ok := find(current, c.Name); !ok || !reflect.DeepEqual(old, c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// removed returns the names of current configs that are no longer configured
+func removed(current, cc []qualifiedConfig) []string {
+	var res []string
+	for _, c := range current {
+		if _, ok := find(cc, c.Name); !ok {
+			res = append(res, c.Name)
+		}
+	}
+	return res
+}
+
+func (r *reloader) createMeters(cc []qualifiedConfig) (map[string]api.Meter, error) {
+	res := make(map[string]api.Meter)
+
+	for _, cc := range changed(r.conf.Meters, cc) {
+		m, err := meter.NewFromConfig(cc.Type, cc.Other)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create meter '%s': %w", cc.Name, err)
+		}
+
+		res[cc.Name] = m
+	}
+
+	return res, nil
+}
+
+func (r *reloader) createChargers(cc []qualifiedConfig) (map[string]api.Charger, error) {
+	res := make(map[string]api.Charger)
+
+	for _, cc := range changed(r.conf.Chargers, cc) {
+		c, err := charger.NewFromConfig(cc.Type, cc.Other)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create charger '%s': %w", cc.Name, err)
+		}
+
+		res[cc.Name] = c
+	}
+
+	return res, nil
+}
+
+func (r *reloader) createVehicles(cc []qualifiedConfig) (map[string]vehicleConfig, error) {
+	res := make(map[string]vehicleConfig)
+
+	for _, cc := range changed(r.conf.Vehicles, cc) {
+		// login routes are registered at startup
+		if _, ok := r.cp.vehicles[cc.Name].(api.ProviderLogin); ok {
+			return nil, fmt.Errorf("vehicle '%s' uses web login: %w", cc.Name, core.ErrRestartRequired)
+		}
+
+		v, err := vehicle.NewFromConfig(cc.Type, cc.Other)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create vehicle '%s': %w", cc.Name, err)
+		}
+
+		if _, ok := v.(api.ProviderLogin); ok {
+			return nil, fmt.Errorf("vehicle '%s' uses web login: %w", cc.Name, core.ErrRestartRequired)
+		}
+
+		res[cc.Name] = vehicleConfig{v, vehicleAccount(cc)}
+	}
+
+	return res, nil
+}
+
+// vehicleConfig is a vehicle with its api account
+type vehicleConfig struct {
+	api.Vehicle
+	account string
+}
+
+// apply adds or replaces the changed instances. Must be executed within the site's control loop.
+// Nothing is replaced if any instance cannot be replaced.
+func (r *reloader) apply(meters map[string]api.Meter, chargers map[string]api.Charger, vehicles map[string]vehicleConfig, tariffs *tariff.Tariffs) error {
+	for name, c := range chargers {
+		if old, ok := r.cp.chargers[name]; ok {
+			if err := r.site.CanReplaceCharger(old, c); err != nil {
+				return fmt.Errorf("cannot replace charger '%s': %w", name, err)
+			}
+		}
+	}
+
+	for name, m := range meters {
+		if old, ok := r.cp.meters[name]; ok {
+			if err := r.site.CanReplaceMeter(old, m); err != nil {
+				return fmt.Errorf("cannot replace meter '%s': %w", name, err)
+			}
+		}
+	}
+
+	for name, c := range chargers {
+		if old, ok := r.cp.chargers[name]; ok {
+			if err := r.site.ReplaceCharger(old, c); err != nil {
+				return fmt.Errorf("cannot replace charger '%s': %w", name, err)
+			}
+
+			devices.Instance.Unregister(old)
+			r.retired = append(r.retired, old)
+		}
+
+		devices.Instance.Register(devices.Charger, name, c)
+		r.cp.chargers[name] = c
+	}
+
+	for name, m := range meters {
+		if old, ok := r.cp.meters[name]; ok {
+			if err := r.site.ReplaceMeter(old, m); err != nil {
+				return fmt.Errorf("cannot replace meter '%s': %w", name, err)
+			}
+
+			devices.Instance.Unregister(old)
+			r.retired = append(r.retired, old)
+		}
+
+		devices.Instance.Register(devices.Meter, name, m)
+		r.cp.meters[name] = m
+	}
+
+	for name, v := range vehicles {
+		if old, ok := r.cp.vehicles[name]; ok {
+			poller.Instance.Unregister(old)
+			r.site.ReplaceVehicle(old, v.Vehicle)
+			devices.Instance.Unregister(old)
+			r.retired = append(r.retired, old)
+		}
+
+		poller.Instance.Register(v.Vehicle, v.account)
+		devices.Instance.Register(devices.Vehicle, name, v.Vehicle)
+		r.cp.vehicles[name] = v.Vehicle
+	}
+
+	if tariffs != nil {
+		log.INFO.Println("tariffs replaced")
+		r.retired = append(r.retired, r.site.GetTariffs())
+		r.site.SetTariffs(*tariffs)
+	}
+
+	return nil
+}
+
+// remove removes devices that are no longer configured. Must be executed within the site's control loop.
+func (r *reloader) remove(conf config) {
+	for _, name := range removed(r.conf.Meters, conf.Meters) {
+		log.INFO.Printf("meter '%s' removed", name)
+		devices.Instance.Unregister(r.cp.meters[name])
+		r.retired = append(r.retired, r.cp.meters[name])
+		delete(r.cp.meters, name)
+	}
+
+	for _, name := range removed(r.conf.Chargers, conf.Chargers) {
+		log.INFO.Printf("charger '%s' removed", name)
+		devices.Instance.Unregister(r.cp.chargers[name])
+		r.retired = append(r.retired, r.cp.chargers[name])
+		delete(r.cp.chargers, name)
+	}
+
+	for _, name := range removed(r.conf.Vehicles, conf.Vehicles) {
+		log.INFO.Printf("vehicle '%s' removed", name)
+		poller.Instance.Unregister(r.cp.vehicles[name])
+		devices.Instance.Unregister(r.cp.vehicles[name])
+		r.retired = append(r.retired, r.cp.vehicles[name])
+		delete(r.cp.vehicles, name)
+	}
+}
+
+// closeUnused closes created instances that have not been applied to the site
+func (r *reloader) closeUnused(meters map[string]api.Meter, chargers map[string]api.Charger, vehicles map[string]vehicleConfig) {
+	for name, m := range meters {
+		if r.cp.meters[name] != m {
+			closeDevice(m)
+		}
+	}
+
+	for name, c := range chargers {
+		if r.cp.chargers[name] != c {
+			closeDevice(c)
+		}
+	}
+
+	for name, v := range vehicles {
+		if r.cp.vehicles[name] != v.Vehicle {
+			closeDevice(v.Vehicle)
+		}
+	}
+}
+
+// closeDevice stops background activity of devices and tariffs that are no longer used
+func closeDevice(dev interface{}) {
+	switch dev := dev.(type) {
+	case tariff.Tariffs:
+		dev.Close()
+	case io.Closer:
+		if err := dev.Close(); err != nil {
+			log.ERROR.Printf("close: %v", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core"
+)
+
+func reloadConfig() config {
+	return config{
+		Meters:   []qualifiedConfig{{Name: "grid", Type: "custom"}, {Name: "spare", Type: "custom"}},
+		Chargers: []qualifiedConfig{{Name: "wallbox", Type: "demo"}},
+		Vehicles: []qualifiedConfig{{Name: "car", Type: "offline"}},
+		Site: map[string]interface{}{
+			"meters": map[string]interface{}{"grid": "grid"},
+		},
+		LoadPoints: []map[string]interface{}{
+			{"charger": "wallbox", "vehicle": "car", "mode": "pv", "enable": map[string]interface{}{"delay": "1m"}},
+		},
+	}
+}
+
+func TestReloadValidate(t *testing.T) {
+	r := &reloader{conf: reloadConfig()}
+
+	// added vehicle and removed unused meter
+	conf := reloadConfig()
+	conf.Vehicles = append(conf.Vehicles, qualifiedConfig{Name: "other", Type: "offline"})
+	conf.Meters = conf.Meters[:1]
+	if err := r.validate(conf); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// removed vehicle in use
+	conf = reloadConfig()
+	conf.Vehicles = nil
+	if err := r.validate(conf); err == nil || errors.Is(err, core.ErrRestartRequired) {
+		t.Errorf("expected in use error, got %v", err)
+	}
+
+	// duplicate names
+	conf = reloadConfig()
+	conf.Chargers = append(conf.Chargers, conf.Chargers[0])
+	if err := r.validate(conf); err == nil {
+		t.Error("expected duplicate name error")
+	}
+
+	// loadpoint settings
+	conf = reloadConfig()
+	conf.LoadPoints[0]["mode"] = "now"
+	conf.LoadPoints[0]["disable"] = map[string]interface{}{"threshold": 500}
+	if err := r.validate(conf); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// other loadpoint changes
+	conf = reloadConfig()
+	conf.LoadPoints[0]["maxcurrent"] = 32
+	if err := r.validate(conf); !errors.Is(err, core.ErrRestartRequired) {
+		t.Errorf("expected restart required, got %v", err)
+	}
+}
+
+func TestReloadLoadPointSettings(t *testing.T) {
+	r := &reloader{conf: reloadConfig()}
+
+	conf := reloadConfig()
+	if settings := r.loadPointSettings(conf.LoadPoints); len(settings) != 0 {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+
+	conf.LoadPoints[0]["mode"] = "now"
+	conf.LoadPoints[0]["enable"] = map[string]interface{}{"delay": "2m", "threshold": -100}
+
+	settings := r.loadPointSettings(conf.LoadPoints)
+	if cc, ok := settings[0]; !ok || cc.Mode != api.ModeNow || cc.Enable.Delay != 2*time.Minute || cc.Enable.Threshold != -100 {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+}
+
+type closeMeter struct {
+	api.Meter
+	closed bool
+}
+
+func (m *closeMeter) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestReloadCloseUnused(t *testing.T) {
+	applied, unused := new(closeMeter), new(closeMeter)
+	r := &reloader{cp: &ConfigProvider{meters: map[string]api.Meter{"grid": applied}}}
+
+	r.closeUnused(map[string]api.Meter{"grid": applied, "pv": unused}, nil, nil)
+
+	if applied.closed || !unused.closed {
+		t.Errorf("expected only unused meter closed, got applied %v unused %v", applied.closed, unused.closed)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/evcc-io/evcc/util/pipe"
 	"github.com/evcc-io/evcc/util/sponsor"
 	"github.com/grandcat/zeroconf"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/spf13/cobra"
@@ -29,8 +30,8 @@ var (
 	log     = util.NewLogger("main")
 	cfgFile string
 
-	ignoreErrors = []string{"warn", "error", "fatal"} // don't add to cache
-	ignoreMqtt   = []string{"auth", "releaseNotes"}   // excessive size may crash certain brokers
+	ignoreErrors = []string{"warn", "error", "fatal"}          // don't add to cache
+	ignoreMqtt   = []string{"auth", "releaseNotes", "devices"} // excessive size may crash certain brokers
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -206,6 +207,12 @@ func run(cmd *cobra.Command, args []string) {
 
 	// metrics
 	if viper.GetBool("metrics") {
+		collector, err := server.NewPrometheus(prometheus.DefaultRegisterer, site.LoadPoints())
+		if err != nil {
+			log.FATAL.Fatal(err)
+		}
+
+		go collector.Run(tee.Attach())
 		httpd.Router().Handle("/metrics", promhttp.Handler())
 	}
 
@@ -242,7 +249,8 @@ func run(cmd *cobra.Command, args []string) {
 	util.CaptureLogs(valueChan)
 
 	// setup messaging
-	pushChan := configureMessengers(conf.Messaging, cache)
+	pushChan, pushHub := configureMessengers(conf.Messaging, conf.Mqtt.RootTopic(), cache)
+	pushHub.Control(site.LoadPoints())
 
 	// set channels
 	site.DumpConfig()
@@ -257,6 +265,24 @@ func run(cmd *cobra.Command, args []string) {
 		close(siteC)
 	}()
 
+	// config reload via api and SIGHUP
+	reloader := &reloader{conf: conf, cp: cp, site: site, hub: pushHub, cache: cache}
+	httpd.RegisterReload(reloader.Reload)
+
+	// device configuration via web ui
+	httpd.RegisterConfig(&configManager{reloader: reloader})
+
+	go func() {
+		signalC := make(chan os.Signal, 1)
+		signal.Notify(signalC, syscall.SIGHUP)
+
+		for range signalC {
+			if err := reloader.Reload(); err != nil {
+				log.ERROR.Printf("config reload: %v", err)
+			}
+		}
+	}()
+
 	// uds health check listener
 	go server.HealthListener(site, siteC)
 
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	paho "github.com/eclipse/paho.mqtt.golang"
@@ -21,6 +23,7 @@ import (
 	"github.com/evcc-io/evcc/util"
 	"github.com/evcc-io/evcc/util/pipe"
 	"github.com/evcc-io/evcc/util/sponsor"
+	"github.com/evcc-io/evcc/util/store"
 	"github.com/spf13/viper"
 	"golang.org/x/text/currency"
 )
@@ -37,6 +40,10 @@ func loadConfigFile(cfgFile string) (conf config, err error) {
 		if err := viper.UnmarshalExact(&conf); err != nil {
 			log.FATAL.Fatalf("failed parsing config file %s: %v", cfgFile, err)
 		}
+
+		if err := mergeManagedConfig(&conf); err != nil {
+			log.FATAL.Fatal(err)
+		}
 	} else {
 		err = errors.New("missing evcc config")
 	}
@@ -50,6 +57,11 @@ func configureEnvironment(conf config) (err error) {
 		err = sponsor.ConfigureSponsorship(conf.SponsorToken)
 	}
 
+	// setup persistent state
+	if err == nil && cfgFile != "" {
+		err = configureStore()
+	}
+
 	// setup mqtt client listener
 	if err == nil && conf.Mqtt.Broker != "" {
 		err = configureMQTT(conf.Mqtt)
@@ -107,6 +119,18 @@ func configureMQTT(conf mqttConfig) error {
 	return nil
 }
 
+// setup persistent state located next to the config file
+func configureStore() error {
+	file := strings.TrimSuffix(cfgFile, filepath.Ext(cfgFile)) + ".state.json"
+
+	var err error
+	if store.Instance, err = store.NewFromFile(file); err != nil {
+		return fmt.Errorf("failed configuring state: %w", err)
+	}
+
+	return nil
+}
+
 // setup javascript
 func configureJavascript(conf map[string]interface{}) error {
 	if err := javascript.Configure(conf); err != nil {
@@ -136,29 +160,58 @@ func configureEEBus(conf map[string]interface{}) error {
 }
 
 // setup messaging
-func configureMessengers(conf messagingConfig, cache *util.Cache) chan push.Event {
+func configureMessengers(conf messagingConfig, root string, cache *util.Cache) (chan push.Event, *push.Hub) {
 	notificationChan := make(chan push.Event, 1)
+	notificationHub, err := createMessengers(conf, root, cache)
+	if err != nil {
+		log.FATAL.Fatal(err)
+	}
+
+	go notificationHub.Run(notificationChan)
+	go notificationHub.Watch(notificationChan, 10*time.Second)
+
+	return notificationChan, notificationHub
+}
+
+// createMessengers creates the push hub and its messengers. Mqtt events are published below the root topic by default.
+func createMessengers(conf messagingConfig, root string, cache *util.Cache) (*push.Hub, error) {
 	notificationHub, err := push.NewHub(conf.Events, cache)
 	if err != nil {
-		log.FATAL.Fatalf("failed configuring push services: %v", err)
+		return nil, fmt.Errorf("failed configuring push services: %w", err)
+	}
+
+	monitor, err := push.NewMonitor(conf.Thresholds, conf.Report, cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed configuring push events: %w", err)
 	}
+	notificationHub.SetMonitor(monitor)
 
 	for _, service := range conf.Services {
+		if strings.EqualFold(service.Type, "mqtt") {
+			other := map[string]interface{}{"topic": root + "/events"}
+			for k, v := range service.Other {
+				other[k] = v
+			}
+			service.Other = other
+		}
+
 		impl, err := push.NewMessengerFromConfig(service.Type, service.Other)
+		if err == nil {
+			impl, err = push.NewRouter(impl, service.Route, service.Quiet)
+		}
 		if err != nil {
-			log.FATAL.Fatal(err)
-			log.FATAL.Fatalf("failed configuring messenger %s: %v", service.Type, err)
+			notificationHub.Stop()
+			return nil, fmt.Errorf("failed configuring messenger %s: %w", service.Type, err)
 		}
 		notificationHub.Add(impl)
 	}
 
-	go notificationHub.Run(notificationChan)
-
-	return notificationChan
+	return notificationHub, nil
 }
 
 func configureTariffs(conf tariffConfig) (tariff.Tariffs, error) {
 	var grid, feedin api.Tariff
+	var co2 api.CO2Intensity
 	var currencyCode currency.Unit = currency.EUR
 	var err error
 
@@ -174,11 +227,15 @@ func configureTariffs(conf tariffConfig) (tariff.Tariffs, error) {
 		feedin, err = tariff.NewFromConfig(conf.FeedIn.Type, conf.FeedIn.Other)
 	}
 
+	if err == nil && conf.CO2.Type != "" {
+		co2, err = tariff.NewCO2FromConfig(conf.CO2.Type, conf.CO2.Other)
+	}
+
 	if err != nil {
 		err = fmt.Errorf("failed configuring tariff: %w", err)
 	}
 
-	tariffs := tariff.NewTariffs(currencyCode, grid, feedin)
+	tariffs := tariff.NewTariffs(currencyCode, grid, feedin, co2)
 
 	return *tariffs, err
 }
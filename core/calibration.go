@@ -0,0 +1,79 @@
+package core
+
+import "math"
+
+const (
+	calibrationTolerance = 1.0 // current below commanded current treated as saturated in A
+	calibrationHeadroom  = 2.0 // current above detected maximum still offered to the vehicle in A
+	calibrationSamples   = 3   // consecutive saturated samples before detecting maximum current
+)
+
+// calibration detects the vehicle's real maximum current by comparing commanded and measured phase currents
+type calibration struct {
+	samples    int     // consecutive saturated samples
+	maxCurrent float64 // detected maximum current per phase, 0 if unknown
+}
+
+// update compares the commanded current with the measured phase currents while charging.
+// It returns true if the detected maximum current has changed.
+func (c *calibration) update(commanded float64, currents []float64) bool {
+	if c == nil || len(currents) == 0 {
+		return false
+	}
+
+	var measured float64
+	for _, i := range currents {
+		measured = math.Max(measured, i)
+	}
+
+	// vehicle is not drawing current
+	if measured <= minActiveCurrent {
+		return false
+	}
+
+	// vehicle follows the commanded current
+	if commanded-measured <= calibrationTolerance {
+		c.samples = 0
+
+		// vehicle draws more than detected
+		if c.maxCurrent > 0 && measured > c.maxCurrent {
+			c.maxCurrent = 0
+			return true
+		}
+
+		return false
+	}
+
+	if c.samples++; c.samples < calibrationSamples {
+		return false
+	}
+
+	prev := c.maxCurrent
+	c.maxCurrent = measured
+
+	return math.Abs(prev-measured) >= calibrationTolerance/2
+}
+
+// limit caps the target current slightly above the detected maximum current
+func (c *calibration) limit(target float64) float64 {
+	if c == nil || c.maxCurrent == 0 {
+		return target
+	}
+	return math.Min(target, c.maxCurrent+calibrationHeadroom)
+}
+
+// saturated returns the detected maximum current per phase if known
+func (c *calibration) saturated() (float64, bool) {
+	if c == nil || c.maxCurrent == 0 {
+		return 0, false
+	}
+	return c.maxCurrent, true
+}
+
+// reset forgets the detected maximum current
+func (c *calibration) reset() {
+	if c != nil {
+		c.samples = 0
+		c.maxCurrent = 0
+	}
+}
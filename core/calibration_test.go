@@ -0,0 +1,89 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/util"
+)
+
+func TestCalibration(t *testing.T) {
+	c := new(calibration)
+
+	// vehicle follows
+	for i := 0; i < calibrationSamples; i++ {
+		if c.update(10, []float64{9.8, 9.7, 9.9}) {
+			t.Error("unexpected detection")
+		}
+	}
+
+	// vehicle not charging
+	for i := 0; i < calibrationSamples; i++ {
+		if c.update(16, []float64{0, 0, 0}) {
+			t.Error("unexpected detection")
+		}
+	}
+
+	// vehicle saturated
+	for i := 1; i <= calibrationSamples; i++ {
+		if detected := c.update(16, []float64{10, 10.2, 9.9}); detected != (i == calibrationSamples) {
+			t.Errorf("sample %d: unexpected detection %v", i, detected)
+		}
+	}
+
+	if max, ok := c.saturated(); !ok || max != 10.2 {
+		t.Errorf("expected 10.2A, got %.3gA", max)
+	}
+
+	if limit := c.limit(16); limit != 10.2+calibrationHeadroom {
+		t.Errorf("unexpected limit %.3gA", limit)
+	}
+
+	if limit := c.limit(8); limit != 8 {
+		t.Errorf("unexpected limit %.3gA", limit)
+	}
+
+	// still saturated at limit
+	if c.update(c.limit(16), []float64{10.1, 10, 10}) {
+		t.Error("unexpected detection")
+	}
+
+	// vehicle draws more than detected
+	if !c.update(c.limit(16), []float64{11.8, 11.9, 12}) {
+		t.Error("expected limit removed")
+	}
+
+	if _, ok := c.saturated(); ok {
+		t.Error("expected unknown max current")
+	}
+}
+
+func TestPvMaxCurrentCalibration(t *testing.T) {
+	Voltage = 100
+
+	lp := &LoadPoint{
+		log:            util.NewLogger("foo"),
+		clock:          clock.NewMock(),
+		MinCurrent:     minA,
+		MaxCurrent:     maxA,
+		Phases:         3,
+		measuredPhases: 3,
+		enabled:        true,
+		status:         api.StatusC,
+		chargeCurrent:  maxA,
+		chargeCurrents: []float64{7, 7, 7},
+		calibration:    &calibration{maxCurrent: 7},
+	}
+
+	// export would allow max current
+	if current := lp.pvMaxCurrent(api.ModePV, -3*maxA*Voltage, false); current != 7+calibrationHeadroom {
+		t.Errorf("expected %.3gA, got %.3gA", 7+calibrationHeadroom, current)
+	}
+
+	// never below min current
+	lp.calibration.maxCurrent = 2
+	if current := lp.pvMaxCurrent(api.ModePV, -3*maxA*Voltage, false); current != minA {
+		t.Errorf("expected %.3gA, got %.3gA", float64(minA), current)
+	}
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Surplus filter types
+const (
+	filterNone   = "none"
+	filterEMA    = "ema"
+	filterMedian = "median"
+)
+
+// ControlConfig defines surplus filtering, ramping and session behaviour for pv charging
+type ControlConfig struct {
+	Filter     string        `mapstructure:"filter"`     // Surplus filter: none, ema, median
+	Cycles     int           `mapstructure:"cycles"`     // Filter window in control cycles
+	Ramp       float64       `mapstructure:"ramp"`       // Maximum current change per cycle in A, 0 for unlimited
+	MinSession time.Duration `mapstructure:"minSession"` // Minimum charging session length before pv mode may disable
+	Calibrate  bool          `mapstructure:"calibrate"`  // Detect vehicle maximum current from charge meter currents
+}
+
+// surplusFilter smoothes available surplus samples
+type surplusFilter interface {
+	Add(float64) float64
+	Reset()
+}
+
+// newSurplusFilter creates a surplus filter from config
+func newSurplusFilter(typ string, cycles int) (surplusFilter, error) {
+	if cycles < 1 {
+		cycles = 1
+	}
+
+	switch strings.ToLower(typ) {
+	case "", filterNone:
+		return nil, nil
+	case filterEMA:
+		return &emaFilter{alpha: 2 / (float64(cycles) + 1)}, nil
+	case filterMedian:
+		return &medianFilter{size: cycles}, nil
+	default:
+		return nil, fmt.Errorf("invalid filter: %s", typ)
+	}
+}
+
+// emaFilter is an exponential moving average filter
+type emaFilter struct {
+	alpha, value float64
+	valid        bool
+}
+
+func (f *emaFilter) Add(v float64) float64 {
+	if f.valid {
+		f.value = f.alpha*v + (1-f.alpha)*f.value
+	} else {
+		f.value, f.valid = v, true
+	}
+	return f.value
+}
+
+func (f *emaFilter) Reset() {
+	f.valid = false
+}
+
+// medianFilter is a moving median filter
+type medianFilter struct {
+	size   int
+	values []float64
+}
+
+func (f *medianFilter) Add(v float64) float64 {
+	if f.values = append(f.values, v); len(f.values) > f.size {
+		f.values = f.values[1:]
+	}
+
+	sorted := append([]float64(nil), f.values...)
+	sort.Float64s(sorted)
+
+	if n := len(sorted); n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[len(sorted)/2]
+}
+
+func (f *medianFilter) Reset() {
+	f.values = nil
+}
+
+// controller applies the control quality settings to pv mode decisions
+type controller struct {
+	ControlConfig
+	filter       surplusFilter
+	sessionStart time.Time
+}
+
+// newController creates a controller from config
+func newController(cc ControlConfig) (*controller, error) {
+	if cc.Ramp < 0 {
+		return nil, fmt.Errorf("invalid ramp: %.3gA", cc.Ramp)
+	}
+
+	filter, err := newSurplusFilter(cc.Filter, cc.Cycles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &controller{
+		ControlConfig: cc,
+		filter:        filter,
+	}, nil
+}
+
+// surplus returns the filtered power available to the loadpoint.
+// Filtering the surplus instead of site power keeps the loadpoint's own charge power changes out of the filter.
+func (c *controller) surplus(available float64) float64 {
+	if c == nil || c.filter == nil {
+		return available
+	}
+	return c.filter.Add(available)
+}
+
+// ramp limits the current change per cycle. Enabling or disabling is not limited.
+func (c *controller) ramp(current, target float64) float64 {
+	if c == nil || c.Ramp == 0 || current == 0 || target == 0 {
+		return target
+	}
+	return math.Max(current-c.Ramp, math.Min(target, current+c.Ramp))
+}
+
+// startSession marks the start of a charging session
+func (c *controller) startSession(now time.Time) {
+	if c != nil {
+		c.sessionStart = now
+	}
+}
+
+// sessionRemaining returns the remaining minimum session duration
+func (c *controller) sessionRemaining(now time.Time) time.Duration {
+	if c == nil || c.sessionStart.IsZero() {
+		return 0
+	}
+
+	if remaining := c.MinSession - now.Sub(c.sessionStart); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// reset clears filter and session state
+func (c *controller) reset() {
+	if c == nil {
+		return
+	}
+	if c.filter != nil {
+		c.filter.Reset()
+	}
+	c.sessionStart = time.Time{}
+}
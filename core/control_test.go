@@ -0,0 +1,121 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/util"
+)
+
+func TestSurplusFilter(t *testing.T) {
+	tc := []struct {
+		typ    string
+		cycles int
+		in     []float64
+		out    float64
+	}{
+		{filterEMA, 3, []float64{1000}, 1000},
+		{filterEMA, 3, []float64{1000, 0}, 500},
+		{filterEMA, 3, []float64{1000, 0, 0}, 250},
+		{filterMedian, 3, []float64{1000, -5000, 800}, 800},
+		{filterMedian, 3, []float64{1000, -5000, 800, 900}, 800},
+		{filterMedian, 4, []float64{1000, 0}, 500},
+	}
+
+	for _, tc := range tc {
+		f, err := newSurplusFilter(tc.typ, tc.cycles)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res float64
+		for _, v := range tc.in {
+			res = f.Add(v)
+		}
+
+		if res != tc.out {
+			t.Errorf("%s %v: expected %.1f, got %.1f", tc.typ, tc.in, tc.out, res)
+		}
+	}
+
+	if _, err := newSurplusFilter("foo", 1); err == nil {
+		t.Error("expected invalid filter error")
+	}
+}
+
+func TestControllerRamp(t *testing.T) {
+	c, err := newController(ControlConfig{Ramp: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := []struct {
+		current, target, res float64
+	}{
+		{0, 16, 16}, // enable
+		{10, 0, 0},  // disable
+		{10, 16, 12},
+		{10, 6, 8},
+		{10, 11, 11},
+	}
+
+	for _, tc := range tc {
+		if res := c.ramp(tc.current, tc.target); res != tc.res {
+			t.Errorf("%+v: expected %.1fA, got %.1fA", tc, tc.res, res)
+		}
+	}
+}
+
+func TestControllerSession(t *testing.T) {
+	c, err := newController(ControlConfig{MinSession: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if res := c.sessionRemaining(now); res != 0 {
+		t.Errorf("expected no session, got %v", res)
+	}
+
+	c.startSession(now)
+	if res := c.sessionRemaining(now.Add(4 * time.Minute)); res != 6*time.Minute {
+		t.Errorf("expected 6m remaining, got %v", res)
+	}
+
+	if res := c.sessionRemaining(now.Add(time.Hour)); res != 0 {
+		t.Errorf("expected session complete, got %v", res)
+	}
+}
+
+func TestPvMaxCurrentFilteredSurplus(t *testing.T) {
+	Voltage = 100
+
+	control, err := newController(ControlConfig{Filter: filterEMA, Cycles: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := &LoadPoint{
+		log:            util.NewLogger("foo"),
+		clock:          clock.NewMock(),
+		MinCurrent:     6,
+		MaxCurrent:     16,
+		Phases:         1,
+		measuredPhases: 1,
+		status:         api.StatusC,
+		enabled:        true,
+		control:        control,
+	}
+
+	// constant 1000W surplus while the loadpoint's own charge power follows the current
+	for i, current := range []float64{6, 10, 10} {
+		lp.chargeCurrent = current
+		lp.chargePower = current * Voltage
+
+		if res := lp.pvMaxCurrent(api.ModePV, lp.chargePower-1000, false); res != 10 {
+			t.Errorf("cycle %d: expected 10A, got %.3gA", i, res)
+		}
+	}
+}
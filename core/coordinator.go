@@ -2,6 +2,7 @@ package core
 
 import (
 	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/poller"
 	"github.com/evcc-io/evcc/util"
 )
 
@@ -39,14 +40,14 @@ func (lp *vehicleCoordinator) availableVehicles(owner interface{}, vehicles []ap
 	return res
 }
 
-// find active vehicle by charge state
-func (lp *vehicleCoordinator) identifyVehicleByStatus(log *util.Logger, owner interface{}, vehicles []api.Vehicle) api.Vehicle {
+// find active vehicle by charge state, reading status through the vehicle api poller
+func (lp *vehicleCoordinator) identifyVehicleByStatus(log *util.Logger, p *poller.Poller, owner interface{}, vehicles []api.Vehicle) api.Vehicle {
 	available := lp.availableVehicles(owner, vehicles)
 
 	var res api.Vehicle
 	for _, vehicle := range available {
-		if vs, ok := vehicle.(api.ChargeState); ok {
-			status, err := vs.Status()
+		if _, ok := vehicle.(api.ChargeState); ok {
+			status, err := p.Status(vehicle)
 
 			if err != nil {
 				log.ERROR.Println("vehicle status:", err)
@@ -0,0 +1,188 @@
+package devices
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+)
+
+// Device types
+const (
+	Charger = "charger"
+	Meter   = "meter"
+	Vehicle = "vehicle"
+)
+
+// Instance is the central device health registry
+var Instance = New()
+
+// Status is the health status of a single device
+type Status struct {
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Healthy     bool      `json:"healthy"`
+	Updated     time.Time `json:"updated"`         // last successful read
+	Failures    int       `json:"failures"`        // consecutive failed reads
+	FailedSince time.Time `json:"failedSince"`     // first failed read since last success
+	Error       string    `json:"error,omitempty"` // last error
+	Latency     float64   `json:"latency"`         // last read duration in ms
+	notified    bool      // failure has been reported
+	recovered   bool      // reported failure has recovered
+}
+
+// Registry tracks the health of all configured devices
+type Registry struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	devices map[interface{}]*Status
+	order   []*Status
+}
+
+// New creates a device registry
+func New() *Registry {
+	return &Registry{
+		clock:   clock.New(),
+		devices: make(map[interface{}]*Status),
+	}
+}
+
+// Register adds the device to the registry. Devices that cannot be used as map keys are ignored.
+func (r *Registry) Register(typ, name string, dev interface{}) {
+	if dev == nil || !reflect.TypeOf(dev).Comparable() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.devices[dev]; ok {
+		return
+	}
+
+	s := &Status{Type: typ, Name: name, Healthy: true}
+	r.devices[dev] = s
+	r.order = append(r.order, s)
+}
+
+// Unregister removes the device from the registry
+func (r *Registry) Unregister(dev interface{}) {
+	if dev == nil || !reflect.TypeOf(dev).Comparable() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.devices[dev]
+	if !ok {
+		return
+	}
+
+	delete(r.devices, dev)
+	for i, o := range r.order {
+		if o == s {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Update records the result of a device read. Unregistered devices are ignored.
+func (r *Registry) Update(dev interface{}, err error, latency time.Duration) {
+	if r == nil || dev == nil || !reflect.TypeOf(dev).Comparable() {
+		return
+	}
+
+	// not a device failure
+	if errors.Is(err, api.ErrNotAvailable) || errors.Is(err, api.ErrMustRetry) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.devices[dev]
+	if !ok {
+		return
+	}
+
+	s.Latency = float64(latency.Microseconds()) / 1e3
+
+	if err == nil {
+		s.Healthy = true
+		s.Updated = r.clock.Now()
+		s.Failures = 0
+		s.FailedSince = time.Time{}
+		s.Error = ""
+		s.recovered = s.recovered || s.notified
+		s.notified = false
+		return
+	}
+
+	if s.Failures == 0 {
+		s.FailedSince = r.clock.Now()
+	}
+
+	s.Healthy = false
+	s.Failures++
+	s.Error = err.Error()
+}
+
+// Measure executes the device read and records its result and latency
+func (r *Registry) Measure(dev interface{}, fun func() error) error {
+	start := time.Now()
+	err := fun()
+	r.Update(dev, err, time.Since(start))
+	return err
+}
+
+// Devices returns the status of all registered devices
+func (r *Registry) Devices() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]Status, 0, len(r.order))
+	for _, s := range r.order {
+		res = append(res, *s)
+	}
+
+	return res
+}
+
+// Failed returns the devices that have been failing for longer than timeout
+// and have not been reported yet. Returned devices are marked as reported.
+func (r *Registry) Failed(timeout time.Duration) []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []Status
+	for _, s := range r.order {
+		if !s.Healthy && !s.notified && r.clock.Since(s.FailedSince) >= timeout {
+			s.notified = true
+			res = append(res, *s)
+		}
+	}
+
+	return res
+}
+
+// Recovered returns the devices that have recovered after their failure has been reported.
+// Returned devices are not reported again.
+func (r *Registry) Recovered() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []Status
+	for _, s := range r.order {
+		if s.recovered && s.Healthy {
+			s.recovered = false
+			res = append(res, *s)
+		}
+	}
+
+	return res
+}
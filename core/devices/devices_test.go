@@ -0,0 +1,77 @@
+package devices
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+)
+
+type device struct{ name string }
+
+func TestRegistry(t *testing.T) {
+	clck := clock.NewMock()
+	r := New()
+	r.clock = clck
+
+	dev := &device{"grid"}
+	r.Register(Meter, "grid", dev)
+
+	// unregistered devices are ignored
+	r.Update(&device{"other"}, errors.New("foo"), 0)
+
+	if res := r.Devices(); len(res) != 1 || !res[0].Healthy {
+		t.Fatalf("unexpected devices: %+v", res)
+	}
+
+	r.Update(dev, nil, 20*time.Millisecond)
+	if s := r.Devices()[0]; !s.Healthy || s.Latency != 20 || !s.Updated.Equal(clck.Now()) {
+		t.Errorf("unexpected status: %+v", s)
+	}
+
+	// not available is not a failure
+	r.Update(dev, api.ErrNotAvailable, 0)
+	if s := r.Devices()[0]; !s.Healthy {
+		t.Errorf("unexpected status: %+v", s)
+	}
+
+	start := clck.Now()
+	r.Update(dev, errors.New("timeout"), time.Second)
+	clck.Add(time.Minute)
+	r.Update(dev, errors.New("timeout"), time.Second)
+
+	if s := r.Devices()[0]; s.Healthy || s.Failures != 2 || s.Error != "timeout" || !s.FailedSince.Equal(start) {
+		t.Errorf("unexpected status: %+v", s)
+	}
+
+	if res := r.Failed(5 * time.Minute); len(res) != 0 {
+		t.Errorf("unexpected failed devices: %+v", res)
+	}
+
+	clck.Add(4 * time.Minute)
+	if res := r.Failed(5 * time.Minute); len(res) != 1 || res[0].Name != "grid" {
+		t.Errorf("expected failed device, got %+v", res)
+	}
+
+	// report once
+	if res := r.Failed(5 * time.Minute); len(res) != 0 {
+		t.Errorf("unexpected failed devices: %+v", res)
+	}
+
+	// recovery
+	r.Update(dev, nil, 0)
+	if s := r.Devices()[0]; !s.Healthy || s.Failures != 0 || s.Error != "" {
+		t.Errorf("unexpected status: %+v", s)
+	}
+
+	if res := r.Recovered(); len(res) != 1 || res[0].Name != "grid" {
+		t.Errorf("expected recovered device, got %+v", res)
+	}
+
+	// report once
+	if res := r.Recovered(); len(res) != 0 {
+		t.Errorf("unexpected recovered devices: %+v", res)
+	}
+}
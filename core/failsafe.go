@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Failsafe modes
+const (
+	failsafeOff  = "off"  // disable charging
+	failsafeMin  = "min"  // reduce enabled chargers to minimum current
+	failsafeKeep = "keep" // keep current charger state
+)
+
+// FailsafeConfig defines the loadpoint state while the grid meter is unavailable
+type FailsafeConfig struct {
+	Cycles int    `mapstructure:"cycles"` // consecutive failed grid meter reads before entering failsafe
+	Mode   string `mapstructure:"mode"`   // off, min or keep
+}
+
+// failsafe tracks consecutive grid meter failures
+type failsafe struct {
+	FailsafeConfig
+	failures int
+}
+
+// newFailsafe creates failsafe from config
+func newFailsafe(cc FailsafeConfig) (*failsafe, error) {
+	if cc.Cycles <= 0 {
+		cc.Cycles = 3
+	}
+
+	switch cc.Mode = strings.ToLower(cc.Mode); cc.Mode {
+	case "":
+		cc.Mode = failsafeMin
+	case failsafeOff, failsafeMin, failsafeKeep:
+	default:
+		return nil, fmt.Errorf("invalid mode: %s", cc.Mode)
+	}
+
+	return &failsafe{FailsafeConfig: cc}, nil
+}
+
+// active returns true if the failure threshold has been reached
+func (f *failsafe) active() bool {
+	return f != nil && f.failures >= f.Cycles
+}
+
+// update records the grid meter result. It returns true if failsafe has just been left.
+func (f *failsafe) update(err error) bool {
+	if f == nil {
+		return false
+	}
+
+	if err != nil {
+		f.failures++
+		return false
+	}
+
+	recovered := f.active()
+	f.failures = 0
+
+	return recovered
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	evbus "github.com/asaskevich/EventBus"
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/mock"
+	"github.com/evcc-io/evcc/util"
+	"github.com/golang/mock/gomock"
+)
+
+func TestFailsafeCycles(t *testing.T) {
+	if _, err := newFailsafe(FailsafeConfig{Mode: "foo"}); err == nil {
+		t.Error("expected invalid mode error")
+	}
+
+	f, err := newFailsafe(FailsafeConfig{Cycles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Mode != failsafeMin {
+		t.Errorf("expected default mode %s, got %s", failsafeMin, f.Mode)
+	}
+
+	failure := errors.New("timeout")
+
+	tc := []struct {
+		err               error
+		active, recovered bool
+	}{
+		{failure, false, false},
+		{nil, false, false},
+		{failure, false, false},
+		{failure, true, false},
+		{failure, true, false},
+		{nil, false, true},
+		{nil, false, false},
+	}
+
+	for i, tc := range tc {
+		recovered := f.update(tc.err)
+		if active := f.active(); active != tc.active || recovered != tc.recovered {
+			t.Errorf("%d: expected active %v recovered %v, got %v %v", i, tc.active, tc.recovered, active, recovered)
+		}
+	}
+
+	// nil-safe
+	var nf *failsafe
+	if nf.update(failure) || nf.active() {
+		t.Error("nil failsafe must not be active")
+	}
+}
+
+func TestFailsafeLoadPoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	charger := mock.NewMockCharger(ctrl)
+
+	lp := &LoadPoint{
+		log:         util.NewLogger("foo"),
+		bus:         evbus.New(),
+		clock:       clock.NewMock(),
+		charger:     charger,
+		wakeUpTimer: NewTimer(),
+		MinCurrent:  minA,
+		MaxCurrent:  maxA,
+	}
+
+	lp.enabled = true
+	lp.chargeCurrent = maxA
+	lp.pvTimer = elapsed
+
+	// min current
+	charger.EXPECT().MaxCurrent(int64(minA)).Return(nil)
+	lp.Failsafe(failsafeMin)
+
+	if !lp.enabled || lp.chargeCurrent != minA || !lp.pvTimer.IsZero() {
+		t.Errorf("unexpected state: enabled %v, current %.0fA", lp.enabled, lp.chargeCurrent)
+	}
+
+	// keep
+	lp.Failsafe(failsafeKeep)
+
+	// off ignores guard duration
+	lp.GuardDuration = time.Hour
+	charger.EXPECT().Enable(false).Return(nil)
+	lp.Failsafe(failsafeOff)
+
+	if lp.enabled {
+		t.Error("expected charger disabled")
+	}
+
+	// min does not enable
+	lp.Failsafe(failsafeMin)
+
+	ctrl.Finish()
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"math"
+)
+
+// FeedInLimitConfig defines the site's grid export limitation (e.g. 70% rule or zero export)
+type FeedInLimitConfig struct {
+	Power     float64 `mapstructure:"power"`     // Maximum grid export power in W, 0 for zero export
+	Tolerance float64 `mapstructure:"tolerance"` // Export power below limit still considered as curtailed in W
+	Step      float64 `mapstructure:"step"`      // Probing power step per cycle in W
+}
+
+// FeedInLimit detects inverter curtailment due to feed-in limitation and probes
+// for the curtailed pv power by stepwise increasing the reported surplus
+type FeedInLimit struct {
+	FeedInLimitConfig
+	probe float64 // Additional surplus power assumed to be available in W
+}
+
+// NewFeedInLimit creates a feed-in limit with sane defaults
+func NewFeedInLimit(cc FeedInLimitConfig) *FeedInLimit {
+	if cc.Tolerance == 0 {
+		cc.Tolerance = 100 // W
+	}
+
+	if cc.Step == 0 {
+		cc.Step = Voltage // W, 1A @ 1p
+	}
+
+	return &FeedInLimit{FeedInLimitConfig: cc}
+}
+
+// Curtailed returns true if grid export sits at the feed-in limit.
+// A discharging battery keeping the grid at the limit is not considered curtailment.
+func (f *FeedInLimit) Curtailed(gridPower, pvPower, batteryPower float64) bool {
+	return pvPower > 0 && batteryPower <= 0 && math.Abs(-gridPower-f.Power) <= f.Tolerance
+}
+
+// Update returns the additional surplus power to assume for the current cycle.
+// While export sits at the limit and vehicles are charging, the surplus is raised
+// by a single step per cycle to ramp up the charge current. While not charging,
+// steps are accumulated up to maxPower until charging can be enabled.
+// On grid import or without curtailment, probing stops and regular control takes over.
+func (f *FeedInLimit) Update(gridPower, pvPower, batteryPower, maxPower float64, charging bool) float64 {
+	switch {
+	case gridPower > f.Tolerance || !f.Curtailed(gridPower, pvPower, batteryPower):
+		f.probe = 0
+
+	case charging:
+		f.probe = math.Min(f.Step, maxPower)
+
+	default:
+		f.probe = math.Min(f.probe+f.Step, maxPower)
+	}
+
+	return f.probe
+}
+
+// Probe returns the current probing power
+func (f *FeedInLimit) Probe() float64 {
+	return f.probe
+}
@@ -0,0 +1,30 @@
+package core
+
+import "testing"
+
+func TestFeedInLimit(t *testing.T) {
+	Voltage = 230
+	f := NewFeedInLimit(FeedInLimitConfig{})
+
+	tc := []struct {
+		grid, pv, battery, max float64
+		charging               bool
+		probe                  float64
+	}{
+		{0, 0, 0, 11000, false, 0},        // night
+		{0, 3000, 0, 11000, false, 230},   // zero export, curtailed
+		{-50, 3000, 0, 11000, false, 460}, // still curtailed, accumulate
+		{0, 3000, 0, 500, false, 500},     // capped
+		{0, 3000, -500, 11000, true, 230}, // charging, single step while battery charging
+		{500, 3000, 0, 11000, true, 0},    // grid import, back off
+		{-1000, 3000, 0, 11000, true, 0},  // uncurtailed surplus (impossible with zero export)
+		{0, 3000, 0, 11000, false, 230},   // curtailed again
+		{0, 3000, 2000, 11000, false, 0},  // battery discharging to keep grid at zero
+	}
+
+	for _, tc := range tc {
+		if probe := f.Update(tc.grid, tc.pv, tc.battery, tc.max, tc.charging); probe != tc.probe {
+			t.Errorf("%+v: expected probe %.0fW, got %.0fW", tc, tc.probe, probe)
+		}
+	}
+}
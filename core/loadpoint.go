@@ -10,12 +10,15 @@ import (
 	"time"
 
 	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/devices"
 	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/core/poller"
 	"github.com/evcc-io/evcc/core/soc"
 	"github.com/evcc-io/evcc/core/wrapper"
 	"github.com/evcc-io/evcc/provider"
 	"github.com/evcc-io/evcc/push"
 	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/store"
 	"github.com/thoas/go-funk"
 
 	evbus "github.com/asaskevich/EventBus"
@@ -25,13 +28,20 @@ import (
 )
 
 const (
-	evChargeStart       = "start"      // update chargeTimer
-	evChargeStop        = "stop"       // update chargeTimer
-	evChargeCurrent     = "current"    // update fakeChargeMeter
-	evChargePower       = "power"      // update chargeRater
-	evVehicleConnect    = "connect"    // vehicle connected
-	evVehicleDisconnect = "disconnect" // vehicle disconnected
-	evVehicleSoC        = "soc"        // vehicle soc progress
+	evChargeStart       = "start"             // update chargeTimer
+	evChargeStop        = "stop"              // update chargeTimer
+	evChargeCurrent     = "current"           // update fakeChargeMeter
+	evChargePower       = "power"             // update chargeRater
+	evVehicleConnect    = "connect"           // vehicle connected
+	evVehicleDisconnect = "disconnect"        // vehicle disconnected
+	evVehicleSoC        = "soc"               // vehicle soc progress
+	evDeviceFailure     = "deviceFailure"     // device failed for longer than timeout
+	evDeviceRecovered   = "deviceRecovered"   // reported device failure recovered
+	evChargerError      = "chargerError"      // charger entered error status E or F
+	evFailsafe          = "failsafe"          // grid meter failsafe entered
+	evFailsafeRecovered = "failsafeRecovered" // grid meter failsafe left
+	evUnauthorized      = "unauthorized"      // identification not authorized to charge
+	evPhaseSwitch       = "phaseSwitch"       // charger phases switched
 
 	pvTimer   = "pv"
 	pvEnable  = "enable"
@@ -80,6 +90,12 @@ type ThresholdConfig struct {
 	Threshold float64
 }
 
+// PhaseSwitchConfig defines the pv mode delays before switching phases
+type PhaseSwitchConfig struct {
+	Scale1pDelay time.Duration `mapstructure:"scale1pDelay"` // defaults to disable delay
+	Scale3pDelay time.Duration `mapstructure:"scale3pDelay"` // defaults to enable delay
+}
+
 // LoadPoint is responsible for controlling charge depending on
 // SoC needs and power availability.
 type LoadPoint struct {
@@ -107,19 +123,26 @@ type LoadPoint struct {
 	OnDisconnect_     interface{} `mapstructure:"onDisconnect"`
 	OnIdentify_       interface{} `mapstructure:"onIdentify"`
 	Enable, Disable   ThresholdConfig
-	ResetOnDisconnect bool `mapstructure:"resetOnDisconnect"`
+	PhaseSwitch       PhaseSwitchConfig    `mapstructure:"phaseSwitch"`
+	Control           ControlConfig        `mapstructure:"control"`
+	VehicleControl    VehicleControlConfig `mapstructure:"vehicleControl"`
+	Precondition      PreconditionConfig   `mapstructure:"precondition"`
+	ResetOnDisconnect bool                 `mapstructure:"resetOnDisconnect"`
 	onDisconnect      api.ActionConfig
 
 	MinCurrent    float64       // PV mode: start current	Min+PV mode: min current
 	MaxCurrent    float64       // Max allowed current. Physically ensured by the charger
 	GuardDuration time.Duration // charger enable/disable minimum holding time
 
-	enabled                bool      // Charger enabled state
-	measuredPhases         int       // Charger physically measured phases
-	chargeCurrent          float64   // Charger current limit
-	guardUpdated           time.Time // Charger enabled/disabled timestamp
-	socUpdated             time.Time // SoC updated timestamp (poll: connected)
-	vehicleConnected       time.Time // Vehicle connected timestamp
+	enabled                bool          // Charger enabled state
+	measuredPhases         int           // Charger physically measured phases
+	learnedPhases          learnedPhases // Active vehicle's learned phases
+	observedPhases         int           // Measured phases observed consecutively since phase switch
+	observedPhasesCount    int           // Number of consecutive observedPhases measurements
+	chargeCurrent          float64       // Charger current limit
+	guardUpdated           time.Time     // Charger enabled/disabled timestamp
+	socUpdated             time.Time     // SoC updated timestamp (poll: connected)
+	vehicleConnected       time.Time     // Vehicle connected timestamp
 	vehicleConnectedTicker *clock.Ticker
 	vehicleID              string
 
@@ -127,11 +150,21 @@ type LoadPoint struct {
 	chargeTimer api.ChargeTimer
 	chargeRater api.ChargeRater
 
-	chargeMeter  api.Meter     // Charger usage meter
-	vehicle      api.Vehicle   // Currently active vehicle
-	vehicles     []api.Vehicle // Assigned vehicles
-	socEstimator *soc.Estimator
-	socTimer     *soc.Timer
+	chargeMeter     api.Meter     // Charger usage meter
+	vehicle         api.Vehicle   // Currently active vehicle
+	vehicles        []api.Vehicle // Assigned vehicles
+	socEstimator    *soc.Estimator
+	socCurveUpdated bool // learned charge curve not yet persisted
+	socTimer        *soc.Timer
+	control         *controller        // pv mode control quality
+	calibration     *calibration       // vehicle maximum current detection
+	vehicleCtrl     *vehicleController // vehicle api charge control
+	preconditioner  *preconditioner    // vehicle climatisation before departure
+	poller          *poller.Poller     // vehicle api reads
+	users           *users             // charging requires authorization if users are defined
+	user            *user              // authorized user of current session
+	userRejected    string             // reason of last rejected authorization of current session
+	number          int                // loadpoint number for user permissions
 
 	// cached state
 	status         api.ChargeStatus       // Charger status
@@ -196,6 +229,28 @@ func NewLoadPointFromConfig(log *util.Logger, cp configProvider, other map[strin
 		lp.log.WARN.Println("maxCurrent must be larger than minCurrent")
 	}
 
+	// pv mode filtering and ramping
+	var err error
+	if lp.control, err = newController(lp.Control); err != nil {
+		return nil, fmt.Errorf("control: %w", err)
+	}
+
+	// vehicle maximum current detection
+	if lp.Control.Calibrate {
+		lp.calibration = new(calibration)
+	}
+
+	// shared vehicle api access
+	lp.poller = poller.Instance
+
+	// vehicle api charge control
+	if lp.vehicleCtrl, err = newVehicleController(lp.clock, lp.VehicleControl); err != nil {
+		return nil, fmt.Errorf("vehicle control: %w", err)
+	}
+
+	// vehicle climatisation before target charge departure
+	lp.preconditioner = newPreconditioner(lp.clock, lp.Precondition)
+
 	// store defaults
 	lp.collectDefaults()
 
@@ -347,8 +402,19 @@ func (lp *LoadPoint) configureChargerType(charger api.Charger) {
 }
 
 // pushEvent sends push messages to clients
-func (lp *LoadPoint) pushEvent(event string) {
-	lp.pushChan <- push.Event{Event: event}
+func (lp *LoadPoint) pushEvent(event string, attr map[string]interface{}) {
+	if attr == nil {
+		attr = make(map[string]interface{})
+	}
+
+	// capture vehicle at event time, the cache may have changed when the event is routed
+	attr["vehicleTitle"] = ""
+	if lp.vehicle != nil {
+		attr["vehicleTitle"] = lp.vehicle.Title()
+	}
+	attr["vehicleIdentity"] = lp.vehicleID
+
+	lp.pushChan <- push.Event{Event: event, Attributes: attr}
 }
 
 // publish sends values to UI and databases
@@ -361,7 +427,7 @@ func (lp *LoadPoint) publish(key string, val interface{}) {
 // evChargeStartHandler sends external start event
 func (lp *LoadPoint) evChargeStartHandler() {
 	lp.log.INFO.Println("start charging ->")
-	lp.pushEvent(evChargeStart)
+	lp.pushEvent(evChargeStart, nil)
 
 	lp.wakeUpTimer.Stop()
 
@@ -372,7 +438,7 @@ func (lp *LoadPoint) evChargeStartHandler() {
 // evChargeStopHandler sends external stop event
 func (lp *LoadPoint) evChargeStopHandler() {
 	lp.log.INFO.Println("stop charging <-")
-	lp.pushEvent(evChargeStop)
+	lp.pushEvent(evChargeStop, nil)
 
 	// soc update reset
 	lp.socUpdated = time.Time{}
@@ -404,9 +470,16 @@ func (lp *LoadPoint) evVehicleConnectHandler() {
 		lp.socEstimator.Reset()
 	}
 
+	// discard site power history
+	lp.control.reset()
+	lp.resetCalibration()
+
 	// flush all vehicles before updating state
 	lp.log.DEBUG.Println("vehicle api refresh")
 	provider.ResetCached()
+	for _, v := range lp.vehicles {
+		lp.poller.Reset(v)
+	}
 
 	// start detection if we have multiple vehicles
 	if len(lp.vehicles) > 1 {
@@ -416,7 +489,7 @@ func (lp *LoadPoint) evVehicleConnectHandler() {
 	// immediately allow pv mode activity
 	lp.elapsePVTimer()
 
-	lp.pushEvent(evVehicleConnect)
+	lp.pushEvent(evVehicleConnect, nil)
 }
 
 // evVehicleDisconnectHandler sends external start event
@@ -430,7 +503,26 @@ func (lp *LoadPoint) evVehicleDisconnectHandler() {
 	lp.publish("chargedEnergy", lp.chargedEnergy)
 	lp.publish("connectedDuration", lp.clock.Since(lp.connectedTime))
 
-	lp.pushEvent(evVehicleDisconnect)
+	lp.pushEvent(evVehicleDisconnect, nil)
+
+	// learn charge efficiency from finished session
+	if lp.socEstimator != nil {
+		if learned := lp.socEstimator.Finish(); learned || lp.socCurveUpdated {
+			lp.saveSocLearning()
+		}
+		lp.socCurveUpdated = false
+	}
+
+	// identification is read again on next connect
+	lp.vehicleID = ""
+	lp.userRejected = ""
+
+	// account session energy to user
+	if lp.user != nil {
+		lp.users.consume(lp.user, lp.number, lp.chargedEnergy, lp.clock.Now())
+		lp.user = nil
+		lp.publish("user", "")
+	}
 
 	// remove active vehicle if we have multiple vehicles
 	if len(lp.vehicles) > 1 {
@@ -455,12 +547,19 @@ func (lp *LoadPoint) evVehicleDisconnectHandler() {
 
 	// reset timer when vehicle is removed
 	lp.socTimer.Reset()
+	lp.preconditioner.reset()
+}
+
+// evPhaseSwitchHandler sends external phase switch event
+func (lp *LoadPoint) evPhaseSwitchHandler(phases int, reason string) {
+	lp.log.INFO.Printf("switched phases: %dp (%s)", phases, reason)
+	lp.pushEvent(evPhaseSwitch, map[string]interface{}{"phases": phases, "reason": reason})
 }
 
 // evVehicleSoCProgressHandler sends external start event
 func (lp *LoadPoint) evVehicleSoCProgressHandler(soc float64) {
 	if lp.progress.NextStep(soc) {
-		lp.pushEvent(evVehicleSoC)
+		lp.pushEvent(evVehicleSoC, nil)
 	}
 }
 
@@ -526,6 +625,7 @@ func (lp *LoadPoint) Prepare(uiChan chan<- util.Param, pushChan chan<- push.Even
 	_ = lp.bus.Subscribe(evVehicleDisconnect, lp.evVehicleDisconnectHandler)
 	_ = lp.bus.Subscribe(evChargeCurrent, lp.evChargeCurrentHandler)
 	_ = lp.bus.Subscribe(evVehicleSoC, lp.evVehicleSoCProgressHandler)
+	_ = lp.bus.Subscribe(evPhaseSwitch, lp.evPhaseSwitchHandler)
 
 	// publish initial values
 	lp.publish("title", lp.Title)
@@ -534,6 +634,7 @@ func (lp *LoadPoint) Prepare(uiChan chan<- util.Param, pushChan chan<- push.Even
 	lp.publish("phases", lp.Phases)
 	lp.publish("activePhases", lp.activePhases())
 	lp.publish("hasVehicle", len(lp.vehicles) > 0)
+	lp.publish("user", "")
 
 	lp.Lock()
 	lp.publish("mode", lp.Mode)
@@ -570,11 +671,14 @@ func (lp *LoadPoint) Prepare(uiChan chan<- util.Param, pushChan chan<- push.Even
 
 // syncCharger updates charger status and synchronizes it with expectations
 func (lp *LoadPoint) syncCharger() {
+	// charger remains enabled if charging is controlled by the vehicle
+	expected := lp.enabled || lp.vehicleCtrl.exclusive(lp.vehicle)
+
 	enabled, err := lp.charger.Enabled()
 	if err == nil {
-		if enabled != lp.enabled {
-			lp.log.WARN.Printf("charger out of sync: expected %vd, got %vd", status[lp.enabled], status[enabled])
-			err = lp.charger.Enable(lp.enabled)
+		if enabled != expected {
+			lp.log.WARN.Printf("charger out of sync: expected %vd, got %vd", status[expected], status[enabled])
+			err = lp.charger.Enable(expected)
 		}
 
 		if !enabled && lp.GetStatus() == api.StatusC {
@@ -583,8 +687,60 @@ func (lp *LoadPoint) syncCharger() {
 	}
 
 	if err != nil {
+		deviceError(deviceCharger, lp.Title)
 		lp.log.ERROR.Printf("charger: %v", err)
 	}
+
+	// repeat rate-limited vehicle commands
+	if sent, err := lp.vehicleCtrl.sync(lp.vehicle); err != nil {
+		lp.log.ERROR.Printf("vehicle remote charge: %v", err)
+	} else if sent {
+		lp.log.DEBUG.Println("vehicle remote charge: synced")
+	}
+
+	// apply target soc as vehicle charge limit
+	if sent, err := lp.vehicleCtrl.setLimit(lp.vehicle, lp.GetTargetSoC()); err != nil {
+		lp.log.ERROR.Printf("vehicle charge limit: %v", err)
+	} else if sent {
+		lp.log.DEBUG.Printf("vehicle charge limit: %d%%", lp.GetTargetSoC())
+	}
+}
+
+// vehicleRemoteCharge starts or stops charging using the vehicle api.
+// Errors are logged but not propagated as the charger remains in control.
+func (lp *LoadPoint) vehicleRemoteCharge(enable bool) {
+	sent, err := lp.vehicleCtrl.enable(lp.vehicle, enable)
+
+	switch {
+	case err != nil:
+		lp.log.ERROR.Printf("vehicle remote charge %s: %v", status[enable], err)
+	case !sent:
+		lp.log.DEBUG.Printf("vehicle remote charge %s: rate limited", status[enable])
+	}
+}
+
+// Failsafe applies the failsafe mode while the site's grid meter is unavailable.
+// The pv timer is reset such that normal enable delays apply after recovery.
+func (lp *LoadPoint) Failsafe(mode string) {
+	var current float64
+
+	switch mode {
+	case failsafeOff:
+		current = 0
+	case failsafeMin:
+		if !lp.enabled {
+			return
+		}
+		current = lp.GetMinCurrent()
+	default:
+		return
+	}
+
+	lp.resetPVTimerIfRunning()
+
+	if err := lp.setLimit(current, true); err != nil {
+		lp.log.ERROR.Printf("failsafe: %v", err)
+	}
 }
 
 // setLimit applies charger current limits and enables/disables accordingly
@@ -615,23 +771,41 @@ func (lp *LoadPoint) setLimit(chargeCurrent float64, force bool) error {
 			return nil
 		}
 
-		// remote stop
-		// TODO https://github.com/evcc-io/evcc/discussions/1929
-		// if car, ok := lp.vehicle.(api.VehicleStopCharge); !enabled && ok {
-		// 	// log but don't propagate
-		// 	if err := car.StopCharge(); err != nil {
-		// 		lp.log.ERROR.Printf("vehicle remote charge stop: %v", err)
-		// 	}
-		// }
-
-		if err := lp.charger.Enable(enabled); err != nil {
-			return fmt.Errorf("charger %s: %w", status[enabled], err)
+		if lp.vehicleCtrl.exclusive(lp.vehicle) {
+			// vehicle only: charger remains enabled
+			sent, err := lp.vehicleCtrl.enable(lp.vehicle, enabled)
+			if err != nil {
+				return fmt.Errorf("vehicle remote charge %s: %w", status[enabled], err)
+			}
+
+			if !sent {
+				lp.log.DEBUG.Printf("vehicle remote charge %s: rate limited", status[enabled])
+				return nil
+			}
+		} else {
+			// remote stop
+			if !enabled && lp.vehicleCtrl.active(lp.vehicle) {
+				lp.vehicleRemoteCharge(false)
+			}
+
+			if err := lp.charger.Enable(enabled); err != nil {
+				return fmt.Errorf("charger %s: %w", status[enabled], err)
+			}
+
+			// remote start
+			if enabled && lp.vehicleCtrl.active(lp.vehicle) {
+				lp.vehicleRemoteCharge(true)
+			}
 		}
 
 		lp.log.DEBUG.Printf("charger %s", status[enabled])
 		lp.enabled = enabled
 		lp.guardUpdated = lp.clock.Now()
 
+		if enabled {
+			lp.control.startSession(lp.guardUpdated)
+		}
+
 		lp.bus.Publish(evChargeCurrent, chargeCurrent)
 
 		// start/stop vehicle wake-up timer
@@ -642,15 +816,6 @@ func (lp *LoadPoint) setLimit(chargeCurrent float64, force bool) error {
 			lp.log.DEBUG.Printf("wake-up timer: stop")
 			lp.wakeUpTimer.Stop()
 		}
-
-		// remote start
-		// TODO https://github.com/evcc-io/evcc/discussions/1929
-		// if car, ok := lp.vehicle.(api.VehicleStartCharge); enabled && ok {
-		// 	// log but don't propagate
-		// 	if err := car.StartCharge(); err != nil {
-		// 		lp.log.ERROR.Printf("vehicle remote charge start: %v", err)
-		// 	}
-		// }
 	}
 
 	return nil
@@ -691,10 +856,34 @@ func (lp *LoadPoint) minSocNotReached() bool {
 		lp.vehicleSoc < float64(lp.SoC.Min)
 }
 
+// updatePrecondition starts or stops vehicle climatisation depending on target charge departure
+func (lp *LoadPoint) updatePrecondition() {
+	if lp.preconditioner == nil {
+		return
+	}
+
+	sent, err := lp.preconditioner.update(lp.vehicle, lp.connected())
+	if err != nil {
+		lp.log.ERROR.Printf("precondition: %v", err)
+	} else if sent && lp.preconditioner.running() {
+		lp.log.INFO.Println("precondition: climatisation started")
+	} else if sent {
+		lp.log.INFO.Println("precondition: climatisation stopped")
+	}
+
+	lp.publish("preconditioning", lp.preconditioner.running())
+}
+
 // climateActive checks if vehicle has active climate request
 func (lp *LoadPoint) climateActive() bool {
-	if cl, ok := lp.vehicle.(api.VehicleClimater); ok {
-		active, outsideTemp, targetTemp, err := cl.Climater()
+	// keep charging from grid while preconditioning
+	if lp.preconditioner.running() {
+		lp.publish("climater", "on")
+		return true
+	}
+
+	if _, ok := lp.vehicle.(api.VehicleClimater); ok {
+		active, outsideTemp, targetTemp, err := lp.poller.Climater(lp.vehicle)
 		if err == nil {
 			lp.log.DEBUG.Printf("climater active: %v, target temp: %.1f°C, outside temp: %.1f°C", active, targetTemp, outsideTemp)
 
@@ -744,6 +933,10 @@ func (lp *LoadPoint) identifyVehicle() {
 	}
 
 	if lp.vehicleID == id {
+		// retry rejected authorization, e.g. once allowed times have started
+		if lp.user == nil {
+			lp.identifyUser(id)
+		}
 		return
 	}
 
@@ -753,6 +946,8 @@ func (lp *LoadPoint) identifyVehicle() {
 	lp.log.DEBUG.Println("charger vehicle id:", id)
 	lp.publish("vehicleIdentity", id)
 
+	lp.identifyUser(id)
+
 	if id != "" {
 		if vehicle := lp.selectVehicleByID(id); vehicle != nil {
 			lp.setActiveVehicle(vehicle)
@@ -760,6 +955,59 @@ func (lp *LoadPoint) identifyVehicle() {
 	}
 }
 
+// identifyUser authorizes the session's user by the charger identification
+func (lp *LoadPoint) identifyUser(id string) {
+	if lp.users == nil || lp.user != nil || id == "" {
+		return
+	}
+
+	u := lp.users.identify(id)
+
+	err := errors.New("unknown identification")
+	if u != nil {
+		err = lp.users.permit(u, lp.number, 0, lp.clock.Now())
+	}
+
+	if err != nil {
+		// notify once per identification and reason while retrying
+		if reason := id + ": " + err.Error(); reason != lp.userRejected {
+			lp.userRejected = reason
+			lp.log.WARN.Printf("user %s not authorized: %v", id, err)
+			lp.pushEvent(evUnauthorized, map[string]interface{}{"identity": id, "reason": err.Error()})
+		}
+		return
+	}
+
+	lp.log.INFO.Println("user authorized:", u.Name)
+	lp.user = u
+	lp.userRejected = ""
+	lp.publish("user", u.Name)
+
+	if authorizer, ok := lp.charger.(api.Authorizer); ok {
+		if err := authorizer.Authorize(id); err != nil {
+			lp.log.ERROR.Printf("charger authorize: %v", err)
+		}
+	}
+}
+
+// authorized returns true if charging is unrestricted or the session's user may charge
+func (lp *LoadPoint) authorized() bool {
+	if lp.users == nil {
+		return true
+	}
+
+	if lp.user == nil {
+		return false
+	}
+
+	if err := lp.users.permit(lp.user, lp.number, lp.chargedEnergy, lp.clock.Now()); err != nil {
+		lp.log.DEBUG.Printf("user: %v", err)
+		return false
+	}
+
+	return true
+}
+
 // selectVehicleByID selects the vehicle with the given ID
 func (lp *LoadPoint) selectVehicleByID(id string) api.Vehicle {
 	// find exact match
@@ -787,6 +1035,61 @@ func (lp *LoadPoint) selectVehicleByID(id string) api.Vehicle {
 	return nil
 }
 
+// socSessionsKey is the store key of the vehicle's charging sessions
+func socSessionsKey(vehicle api.Vehicle) string {
+	return "vehicle." + vehicle.Title() + ".sessions"
+}
+
+// socCurveKey is the store key of the vehicle's learned charge curve
+func socCurveKey(vehicle api.Vehicle) string {
+	return "vehicle." + vehicle.Title() + ".curve"
+}
+
+// newSocEstimator creates the vehicle's soc estimator using its recorded charging sessions and charge curve
+func (lp *LoadPoint) newSocEstimator(vehicle api.Vehicle) *soc.Estimator {
+	se := soc.NewEstimator(lp.log, lp.charger, lp.poller.Vehicle(vehicle), lp.SoC.Estimate)
+
+	var sessions []soc.Session
+	if _, err := store.Instance.Get(socSessionsKey(vehicle), &sessions); err != nil {
+		lp.log.ERROR.Printf("charging sessions: %v", err)
+	}
+
+	if len(sessions) > 0 {
+		se.Learn(sessions...)
+	}
+
+	// configured curve takes precedence, read from unwrapped vehicle
+	if vc, ok := vehicle.(api.VehicleChargeCurve); ok && len(vc.ChargeCurve()) > 0 {
+		se.SetCurve(vc.ChargeCurve())
+	}
+
+	var curve []api.ChargeCurvePoint
+	if _, err := store.Instance.Get(socCurveKey(vehicle), &curve); err != nil {
+		lp.log.ERROR.Printf("charge curve: %v", err)
+	}
+
+	if len(curve) > 0 {
+		se.LearnCurve(curve)
+	}
+
+	return se
+}
+
+// saveSocLearning persists the active vehicle's charging sessions and learned charge curve
+func (lp *LoadPoint) saveSocLearning() {
+	if lp.vehicle == nil {
+		return
+	}
+
+	if err := store.Instance.Set(socSessionsKey(lp.vehicle), lp.socEstimator.Sessions()); err != nil {
+		lp.log.ERROR.Printf("charging sessions: %v", err)
+	}
+
+	if err := store.Instance.Set(socCurveKey(lp.vehicle), lp.socEstimator.LearnedCurve()); err != nil {
+		lp.log.ERROR.Printf("charge curve: %v", err)
+	}
+}
+
 // setActiveVehicle assigns currently active vehicle and configures soc estimator
 func (lp *LoadPoint) setActiveVehicle(vehicle api.Vehicle) {
 	if lp.vehicle == vehicle {
@@ -805,8 +1108,16 @@ func (lp *LoadPoint) setActiveVehicle(vehicle api.Vehicle) {
 	}
 	lp.log.INFO.Printf("vehicle updated: %s -> %s", from, to)
 
+	// forget commands sent to previous vehicle
+	lp.vehicleCtrl.reset()
+	lp.preconditioner.reset()
+	lp.preconditioner.setDeparture(lp.socTimer.Time)
+	lp.resetCalibration()
+
+	lp.loadLearnedPhases(vehicle)
+
 	if lp.vehicle = vehicle; vehicle != nil {
-		lp.socEstimator = soc.NewEstimator(lp.log, lp.charger, vehicle, lp.SoC.Estimate)
+		lp.socEstimator = lp.newSocEstimator(vehicle)
 
 		lp.publish("vehiclePresent", true)
 		lp.publish("vehicleTitle", lp.vehicle.Title())
@@ -886,7 +1197,7 @@ func (lp *LoadPoint) identifyVehicleByStatus() {
 		return
 	}
 
-	if vehicle := coordinator.identifyVehicleByStatus(lp.log, lp, lp.vehicles); vehicle != nil {
+	if vehicle := coordinator.identifyVehicleByStatus(lp.log, lp.poller, lp, lp.vehicles); vehicle != nil {
 		lp.setActiveVehicle(vehicle)
 		return
 	}
@@ -899,7 +1210,11 @@ func (lp *LoadPoint) identifyVehicleByStatus() {
 
 // updateChargerStatus updates charger status and detects car connected/disconnected events
 func (lp *LoadPoint) updateChargerStatus() error {
-	status, err := lp.charger.Status()
+	var status api.ChargeStatus
+	err := devices.Instance.Measure(lp.charger, func() (err error) {
+		status, err = lp.charger.Status()
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -932,6 +1247,12 @@ func (lp *LoadPoint) updateChargerStatus() error {
 			lp.bus.Publish(evVehicleDisconnect)
 		}
 
+		// changed to E or F - charger error
+		if status == api.StatusE || status == api.StatusF {
+			lp.log.WARN.Printf("charger error status: %s", status)
+			lp.pushEvent(evChargerError, map[string]interface{}{"chargerStatus": string(status)})
+		}
+
 		// update whenever there is a state change
 		lp.bus.Publish(evChargeCurrent, lp.chargeCurrent)
 	}
@@ -981,9 +1302,9 @@ func (lp *LoadPoint) resetPVTimerIfRunning(typ ...string) {
 }
 
 // scalePhasesIfAvailable scales if api.ChargePhases is available
-func (lp *LoadPoint) scalePhasesIfAvailable(phases int) error {
+func (lp *LoadPoint) scalePhasesIfAvailable(phases int, reason string) error {
 	if _, ok := lp.charger.(api.ChargePhases); ok {
-		return lp.scalePhases(phases)
+		return lp.scalePhases(phases, reason)
 	}
 
 	return nil
@@ -1001,12 +1322,13 @@ func (lp *LoadPoint) setPhases(phases int) {
 		lp.publishTimer(phaseTimer, 0, timerInactive)
 
 		lp.resetMeasuredPhases()
+		lp.resetCalibration()
 	}
 }
 
 // scalePhases adjusts the number of active phases and returns the appropriate charging current.
 // Returns api.ErrNotAvailable if api.ChargePhases is not available.
-func (lp *LoadPoint) scalePhases(phases int) error {
+func (lp *LoadPoint) scalePhases(phases int, reason string) error {
 	cp, ok := lp.charger.(api.ChargePhases)
 	if !ok {
 		panic("charger does not implement api.ChargePhases")
@@ -1025,6 +1347,7 @@ func (lp *LoadPoint) scalePhases(phases int) error {
 
 		// update setting
 		lp.setPhases(phases)
+		lp.bus.Publish(evPhaseSwitch, phases, reason)
 
 		// allow pv mode to re-enable charger right away
 		lp.elapsePVTimer()
@@ -1049,8 +1372,8 @@ func (lp *LoadPoint) pvScalePhases(availablePower, minCurrent, maxCurrent float6
 	var waiting bool
 	activePhases := lp.activePhases()
 
-	// scale down phases
-	if targetCurrent := powerToCurrent(availablePower, activePhases); targetCurrent < minCurrent && activePhases > 1 {
+	// scale down phases unless vehicle is known to keep charging on multiple phases
+	if targetCurrent := powerToCurrent(availablePower, activePhases); targetCurrent < minCurrent && activePhases > 1 && !lp.getLearnedPhases().Ignore1p {
 		lp.log.DEBUG.Printf("available power %.0fW < %.0fW min %dp threshold", availablePower, float64(activePhases)*Voltage*minCurrent, activePhases)
 
 		if lp.phaseTimer.IsZero() {
@@ -1058,12 +1381,13 @@ func (lp *LoadPoint) pvScalePhases(availablePower, minCurrent, maxCurrent float6
 			lp.phaseTimer = lp.clock.Now()
 		}
 
-		lp.publishTimer(phaseTimer, lp.Disable.Delay, phaseScale1p)
+		delay := lp.phaseSwitchDelay(1)
+		lp.publishTimer(phaseTimer, delay, phaseScale1p)
 
 		elapsed := lp.clock.Since(lp.phaseTimer)
-		if elapsed >= lp.Disable.Delay {
+		if elapsed >= delay {
 			lp.log.DEBUG.Printf("phase %s timer elapsed", phaseScale1p)
-			if err := lp.scalePhases(1); err == nil {
+			if err := lp.scalePhases(1, phaseReasonDeficit); err == nil {
 				lp.log.DEBUG.Printf("switched phases: 1p @ %.0fW", availablePower)
 			} else {
 				lp.log.ERROR.Printf("switch phases: %v", err)
@@ -1087,12 +1411,13 @@ func (lp *LoadPoint) pvScalePhases(availablePower, minCurrent, maxCurrent float6
 			lp.phaseTimer = lp.clock.Now()
 		}
 
-		lp.publishTimer(phaseTimer, lp.Enable.Delay, phaseScale3p)
+		delay := lp.phaseSwitchDelay(3)
+		lp.publishTimer(phaseTimer, delay, phaseScale3p)
 
 		elapsed := lp.clock.Since(lp.phaseTimer)
-		if elapsed >= lp.Enable.Delay {
+		if elapsed >= delay {
 			lp.log.DEBUG.Printf("phase %s timer elapsed", phaseScale3p)
-			if err := lp.scalePhases(3); err == nil {
+			if err := lp.scalePhases(3, phaseReasonSurplus); err == nil {
 				lp.log.DEBUG.Printf("switched phases: 3p @ %.0fW", availablePower)
 			} else {
 				lp.log.ERROR.Printf("switch phases: %v", err)
@@ -1152,6 +1477,14 @@ func (lp *LoadPoint) pvMaxCurrent(mode api.ChargeMode, sitePower float64, batter
 		}
 	}
 
+	// smooth available surplus to avoid reacting to short-term fluctuations
+	available := -sitePower + lp.chargePower
+	if filtered := lp.control.surplus(available); filtered != available {
+		sitePower = lp.chargePower - filtered
+		lp.log.DEBUG.Printf("filtered site power: %.0fW (%.0fW surplus)", sitePower, filtered)
+		lp.publish("controlSitePower", sitePower)
+	}
+
 	// calculate target charge current from delta power and actual current
 	effectiveCurrent := lp.effectiveCurrent()
 	activePhases := lp.activePhases()
@@ -1160,6 +1493,12 @@ func (lp *LoadPoint) pvMaxCurrent(mode api.ChargeMode, sitePower float64, batter
 
 	lp.log.DEBUG.Printf("pv charge current: %.3gA = %.3gA + %.3gA (%.0fW @ %dp)", targetCurrent, effectiveCurrent, deltaCurrent, sitePower, activePhases)
 
+	// don't offer current the vehicle cannot draw, leaving the surplus to other loadpoints
+	if limited := math.Max(lp.calibration.limit(targetCurrent), minCurrent); limited < targetCurrent {
+		lp.log.DEBUG.Printf("pv charge current: %.3gA limited by vehicle max current", limited)
+		targetCurrent = limited
+	}
+
 	// in MinPV mode or under special conditions return at least minCurrent
 	if (mode == api.ModeMinPV || batteryBuffered || lp.climateActive()) && targetCurrent < minCurrent {
 		return minCurrent
@@ -1179,6 +1518,15 @@ func (lp *LoadPoint) pvMaxCurrent(mode api.ChargeMode, sitePower float64, batter
 
 			elapsed := lp.clock.Since(lp.pvTimer)
 			if elapsed >= lp.Disable.Delay {
+				// keep charging until minimum session length is reached
+				remaining := lp.control.sessionRemaining(lp.clock.Now())
+				lp.publish("controlSessionRemaining", remaining)
+
+				if remaining > 0 {
+					lp.log.DEBUG.Printf("pv disable timer elapsed, minimum session remaining: %v", remaining.Round(time.Second))
+					return minCurrent
+				}
+
 				lp.log.DEBUG.Println("pv disable timer elapsed")
 				return 0
 			}
@@ -1234,12 +1582,33 @@ func (lp *LoadPoint) pvMaxCurrent(mode api.ChargeMode, sitePower float64, batter
 	// cap at maximum current
 	targetCurrent = math.Min(targetCurrent, maxCurrent)
 
+	// limit current change per cycle
+	if lp.enabled {
+		if limited := lp.control.ramp(lp.chargeCurrent, targetCurrent); limited != targetCurrent {
+			lp.log.DEBUG.Printf("pv charge current ramp limited: %.3gA (%.3gA target)", limited, targetCurrent)
+			targetCurrent = limited
+		}
+	}
+	lp.publish("controlTargetCurrent", targetCurrent)
+
 	return targetCurrent
 }
 
 // UpdateChargePower updates charge meter power
 func (lp *LoadPoint) UpdateChargePower() {
-	err := retry.Do(func() error {
+	err := devices.Instance.Measure(lp.chargeMeter, func() error {
+		return lp.updateChargePower()
+	})
+
+	if err != nil {
+		deviceError(deviceMeter, lp.Title)
+		lp.log.ERROR.Printf("charge meter: %v", err)
+	}
+}
+
+// updateChargePower reads charge meter power with retries
+func (lp *LoadPoint) updateChargePower() error {
+	return retry.Do(func() error {
 		value, err := lp.chargeMeter.CurrentPower()
 		if err != nil {
 			return err
@@ -1258,11 +1627,7 @@ func (lp *LoadPoint) UpdateChargePower() {
 		}
 
 		return nil
-	}, retryOptions...)
-
-	if err != nil {
-		lp.log.ERROR.Printf("charge meter: %v", err)
-	}
+	}, deviceRetryOptions(deviceMeter, lp.Title)...)
 }
 
 // updateChargeCurrents uses MeterCurrent interface to count phases with current >=1A
@@ -1291,10 +1656,12 @@ func (lp *LoadPoint) updateChargeCurrents() {
 		}
 
 		var phases int
+		var current float64
 		for _, i := range lp.chargeCurrents {
 			if i > minActiveCurrent {
 				phases++
 			}
+			current = math.Max(current, i)
 		}
 
 		if phases >= 1 {
@@ -1304,7 +1671,28 @@ func (lp *LoadPoint) updateChargeCurrents() {
 
 			lp.log.DEBUG.Printf("detected phases: %dp", phases)
 			lp.publish("activePhases", phases)
+
+			lp.learnVehiclePhases(phases, current)
 		}
+
+		// compare commanded with measured current
+		if lp.enabled && lp.calibration.update(lp.chargeCurrent, lp.chargeCurrents) {
+			maxCurrent, ok := lp.calibration.saturated()
+			if ok {
+				lp.log.INFO.Printf("detected vehicle max current: %.3gA", maxCurrent)
+			} else {
+				lp.log.INFO.Println("vehicle max current exceeded, removing limit")
+			}
+			lp.publish("vehicleMaxCurrent", maxCurrent)
+		}
+	}
+}
+
+// resetCalibration forgets the detected vehicle maximum current
+func (lp *LoadPoint) resetCalibration() {
+	if _, ok := lp.calibration.saturated(); ok {
+		lp.calibration.reset()
+		lp.publish("vehicleMaxCurrent", 0.0)
 	}
 }
 
@@ -1350,16 +1738,32 @@ func (lp *LoadPoint) socProvidedByCharger() bool {
 	return false
 }
 
+// sampleChargeCurve learns the charge power taper from power offered by the charger once per cycle
+func (lp *LoadPoint) sampleChargeCurve() {
+	var offered float64
+	if lp.enabled && lp.charging() {
+		offered = lp.chargeCurrent * float64(lp.activePhases()) * Voltage
+	}
+
+	minPower := lp.GetMinCurrent() * float64(lp.activePhases()) * Voltage
+
+	if lp.socEstimator.SampleCurve(lp.chargePower, offered, minPower) {
+		lp.socCurveUpdated = true
+	}
+}
+
 // publish state of charge, remaining charge duration and range
 func (lp *LoadPoint) publishSoCAndRange() {
 	if lp.socEstimator == nil {
 		return
 	}
 
+	lp.sampleChargeCurve()
+
 	if lp.socPollAllowed() || lp.socProvidedByCharger() {
 		lp.socUpdated = lp.clock.Now()
 
-		f, err := lp.socEstimator.SoC(lp.chargedEnergy)
+		f, err := lp.socEstimator.SoC(lp.chargedEnergy, lp.chargePower)
 		if err == nil {
 			lp.vehicleSoc = math.Trunc(f)
 			lp.log.DEBUG.Printf("vehicle soc: %.0f%%", lp.vehicleSoc)
@@ -1371,23 +1775,25 @@ func (lp *LoadPoint) publishSoCAndRange() {
 				lp.setRemainingDuration(-1)
 			}
 
-			lp.setRemainingEnergy(1e3 * lp.socEstimator.RemainingChargeEnergy(lp.SoC.Target))
+			// energy depends on charge power, assume maximum power if not charging
+			power := lp.chargePower
+			if !lp.charging() {
+				power = lp.GetMaxPower()
+			}
+
+			lp.setRemainingEnergy(1e3 * lp.socEstimator.RemainingChargeEnergy(power, lp.SoC.Target))
 
 			// range
-			if vs, ok := lp.vehicle.(api.VehicleRange); ok {
-				if rng, err := vs.Range(); err == nil {
-					lp.log.DEBUG.Printf("vehicle range: %dkm", rng)
-					lp.publish("vehicleRange", rng)
-				}
+			if rng, err := lp.poller.Range(lp.vehicle); err == nil {
+				lp.log.DEBUG.Printf("vehicle range: %dkm", rng)
+				lp.publish("vehicleRange", rng)
 			}
 
 			// odometer
 			// TODO read only once after connect
-			if vs, ok := lp.vehicle.(api.VehicleOdometer); ok {
-				if odo, err := vs.Odometer(); err == nil {
-					lp.log.DEBUG.Printf("vehicle odometer: %.0fkm", odo)
-					lp.publish("vehicleOdometer", odo)
-				}
+			if odo, err := lp.poller.Odometer(lp.vehicle); err == nil {
+				lp.log.DEBUG.Printf("vehicle odometer: %.0fkm", odo)
+				lp.publish("vehicleOdometer", odo)
 			}
 
 			// trigger message after variables are updated
@@ -1396,6 +1802,7 @@ func (lp *LoadPoint) publishSoCAndRange() {
 			if errors.Is(err, api.ErrMustRetry) {
 				lp.socUpdated = time.Time{}
 			} else {
+				deviceError(deviceVehicle, lp.vehicle.Title())
 				lp.log.ERROR.Printf("vehicle soc: %v", err)
 			}
 		}
@@ -1404,6 +1811,19 @@ func (lp *LoadPoint) publishSoCAndRange() {
 	}
 }
 
+// publishVehicleDataAge publishes the age of the vehicle api values
+func (lp *LoadPoint) publishVehicleDataAge() {
+	if lp.vehicle == nil {
+		return
+	}
+
+	for key := range lp.poller.Values(lp.vehicle) {
+		if age := lp.poller.Age(lp.vehicle, key); age >= 0 {
+			lp.publish("vehicle"+key+"Age", age.Truncate(time.Second))
+		}
+	}
+}
+
 // Update is the main control function. It reevaluates meters and charger state
 func (lp *LoadPoint) Update(sitePower float64, cheap bool, batteryBuffered bool) {
 	mode := lp.GetMode()
@@ -1422,10 +1842,12 @@ func (lp *LoadPoint) Update(sitePower float64, cheap bool, batteryBuffered bool)
 
 	// read and publish status
 	if err := lp.updateChargerStatus(); err != nil {
+		deviceError(deviceCharger, lp.Title)
 		lp.log.ERROR.Printf("charger: %v", err)
 		return
 	}
 
+	lp.publish("status", lp.GetStatus())
 	lp.publish("connected", lp.connected())
 	lp.publish("charging", lp.charging())
 	lp.publish("enabled", lp.enabled)
@@ -1444,10 +1866,14 @@ func (lp *LoadPoint) Update(sitePower float64, cheap bool, batteryBuffered bool)
 	// publish soc after updating charger status to make sure
 	// initial update of connected state matches charger status
 	lp.publishSoCAndRange()
+	lp.publishVehicleDataAge()
 
 	// sync settings with charger
 	lp.syncCharger()
 
+	// start vehicle climatisation before departure
+	lp.updatePrecondition()
+
 	// check if car connected and ready for charging
 	var err error
 
@@ -1464,6 +1890,10 @@ func (lp *LoadPoint) Update(sitePower float64, cheap bool, batteryBuffered bool)
 		// https://github.com/evcc-io/evcc/issues/105
 		err = lp.setLimit(0, false)
 
+	// users must be authorized before charging
+	case !lp.authorized():
+		err = lp.setLimit(0, true)
+
 	case lp.targetSocReached():
 		lp.log.DEBUG.Printf("targetSoC reached: %.1f > %d", lp.vehicleSoc, lp.SoC.Target)
 		var targetCurrent float64 // zero disables
@@ -1484,21 +1914,21 @@ func (lp *LoadPoint) Update(sitePower float64, cheap bool, batteryBuffered bool)
 
 	case lp.minSocNotReached():
 		// 3p if available
-		if err = lp.scalePhasesIfAvailable(3); err == nil {
+		if err = lp.scalePhasesIfAvailable(3, phaseReasonMinSoC); err == nil {
 			err = lp.setLimit(lp.GetMaxCurrent(), true)
 		}
 		lp.elapsePVTimer() // let PV mode disable immediately afterwards
 
 	case mode == api.ModeNow:
 		// 3p if available
-		if err = lp.scalePhasesIfAvailable(3); err == nil {
+		if err = lp.scalePhasesIfAvailable(3, phaseReasonNow); err == nil {
 			err = lp.setLimit(lp.GetMaxCurrent(), true)
 		}
 
 	// target charging
 	case lp.socTimer.DemandActive():
 		// 3p if available
-		if err = lp.scalePhasesIfAvailable(3); err == nil {
+		if err = lp.scalePhasesIfAvailable(3, phaseReasonTarget); err == nil {
 			targetCurrent := lp.socTimer.Handle()
 			err = lp.setLimit(targetCurrent, true)
 		}
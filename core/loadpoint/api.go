@@ -46,8 +46,16 @@ type API interface {
 	// SetPhases sets the enabled phases
 	SetPhases(int) error
 
+	// GetTargetTime returns the target charging time
+	GetTargetTime() time.Time
 	// SetTargetCharge sets the charge targetSoC
 	SetTargetCharge(time.Time, int)
+	// GetVehicle returns the active vehicle
+	GetVehicle() api.Vehicle
+	// GetVehicles returns the assigned vehicles
+	GetVehicles() []api.Vehicle
+	// GetVehicleSoC returns the active vehicle's soc
+	GetVehicleSoC() float64
 	// SetVehicle sets the active vehicle
 	SetVehicle(vehicle api.Vehicle)
 	// RemoteControl sets remote status demand
@@ -112,13 +112,20 @@ func (lp *LoadPoint) SetPhases(phases int) error {
 	}
 
 	if _, ok := lp.charger.(api.ChargePhases); ok {
-		return lp.scalePhases(phases)
+		return lp.scalePhases(phases, phaseReasonManual)
 	}
 
 	lp.setPhases(phases)
 	return nil
 }
 
+// GetTargetTime returns the target charging time
+func (lp *LoadPoint) GetTargetTime() time.Time {
+	lp.Lock()
+	defer lp.Unlock()
+	return lp.socTimer.Time
+}
+
 // SetTargetCharge sets loadpoint charge targetSoC
 func (lp *LoadPoint) SetTargetCharge(finishAt time.Time, soc int) {
 	lp.Lock()
@@ -129,6 +136,7 @@ func (lp *LoadPoint) SetTargetCharge(finishAt time.Time, soc int) {
 	// apply immediately
 	if lp.socTimer.Time != finishAt || lp.SoC.Target != soc {
 		lp.socTimer.Set(finishAt)
+		lp.preconditioner.setDeparture(finishAt)
 
 		// don't remove soc
 		if !finishAt.IsZero() {
@@ -139,6 +147,27 @@ func (lp *LoadPoint) SetTargetCharge(finishAt time.Time, soc int) {
 	}
 }
 
+// GetVehicle returns the active vehicle
+func (lp *LoadPoint) GetVehicle() api.Vehicle {
+	lp.Lock()
+	defer lp.Unlock()
+	return lp.vehicle
+}
+
+// GetVehicles returns the assigned vehicles
+func (lp *LoadPoint) GetVehicles() []api.Vehicle {
+	lp.Lock()
+	defer lp.Unlock()
+	return append([]api.Vehicle(nil), lp.vehicles...)
+}
+
+// GetVehicleSoC returns the active vehicle's soc
+func (lp *LoadPoint) GetVehicleSoC() float64 {
+	lp.Lock()
+	defer lp.Unlock()
+	return lp.vehicleSoc
+}
+
 // SetVehicle sets the active vehicle
 func (lp *LoadPoint) SetVehicle(vehicle api.Vehicle) {
 	lp.Lock()
@@ -2,14 +2,42 @@ package core
 
 import (
 	"math"
+	"time"
 
 	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/util/store"
+)
+
+// Phase switch reasons
+const (
+	phaseReasonDeficit = "insufficient pv power for 3p"
+	phaseReasonSurplus = "sufficient pv power for 3p"
+	phaseReasonMinSoC  = "minimum soc not reached"
+	phaseReasonNow     = "fast charging"
+	phaseReasonTarget  = "target charging"
+	phaseReasonManual  = "manual"
+)
+
+// learnedPhases are the phases observed while a vehicle was charging
+type learnedPhases struct {
+	Phases   int       `json:"phases"`   // phases used while charger is on 3p
+	Ignore1p bool      `json:"ignore1p"` // vehicle kept charging on multiple phases after switching to 1p
+	Updated  time.Time `json:"updated"`  // last confirmation of learned phases
+}
+
+const (
+	learnPhasesCycles    = 3                   // consecutive cycles with unchanged measured phases before vehicle phases are learned
+	learnPhasesTolerance = 2.0                 // maximum deviation of measured from commanded current in A for learning
+	learnPhasesRefresh   = 24 * time.Hour      // interval for persisting confirmed learned phases
+	learnPhasesExpiry    = 30 * 24 * time.Hour // learned phases are discarded if not confirmed within
 )
 
 // resetMeasuredPhases resets measured phases to unknown on vehicle disconnect, phase switch or phase api call
 func (lp *LoadPoint) resetMeasuredPhases() {
 	lp.Lock()
 	lp.measuredPhases = 0
+	lp.observedPhases = 0
+	lp.observedPhasesCount = 0
 	lp.Unlock()
 
 	lp.publish("activePhases", lp.activePhases())
@@ -49,14 +77,24 @@ func (lp *LoadPoint) activePhases() int {
 	vehicle := lp.getVehiclePhases()
 	measured := lp.getMeasuredPhases()
 
+	// vehicle is known to ignore 1p
+	if physical == 1 && lp.getLearnedPhases().Ignore1p {
+		physical = 0
+	}
+
 	return min(expect(vehicle), expect(physical), expect(measured))
 }
 
 // maxActivePhases returns the maximum number of active phases for the meter.
+// Learned vehicle phases are not considered to never block scaling up.
 func (lp *LoadPoint) maxActivePhases() int {
 	physical := lp.GetPhases()
 	measured := lp.getMeasuredPhases()
-	vehicle := lp.getVehiclePhases()
+
+	var vehicle int
+	if lp.vehicle != nil {
+		vehicle = lp.vehicle.Phases()
+	}
 
 	// during 1p or unknown config, 1p measured is not a restriction
 	if physical <= 1 || vehicle == 1 {
@@ -71,10 +109,116 @@ func (lp *LoadPoint) maxActivePhases() int {
 	return min(expect(vehicle), expect(physical), expect(measured))
 }
 
+// getVehiclePhases returns the learned or configured vehicle phases
 func (lp *LoadPoint) getVehiclePhases() int {
 	if lp.vehicle != nil {
+		if learned := lp.getLearnedPhases(); learned.Phases > 0 {
+			return learned.Phases
+		}
 		return lp.vehicle.Phases()
 	}
 
 	return 0
 }
+
+// getLearnedPhases provides synchronized access to learnedPhases
+func (lp *LoadPoint) getLearnedPhases() learnedPhases {
+	lp.Lock()
+	defer lp.Unlock()
+	return lp.learnedPhases
+}
+
+// learnedPhasesKey is the store key of the vehicle's learned phases
+func learnedPhasesKey(vehicle api.Vehicle) string {
+	return "vehicle." + vehicle.Title() + ".phases"
+}
+
+// loadLearnedPhases restores the vehicle's learned phases unless expired
+func (lp *LoadPoint) loadLearnedPhases(vehicle api.Vehicle) {
+	var learned learnedPhases
+	if vehicle != nil {
+		if _, err := store.Instance.Get(learnedPhasesKey(vehicle), &learned); err != nil {
+			lp.log.ERROR.Printf("learned phases: %v", err)
+		}
+	}
+
+	if lp.clock.Since(learned.Updated) > learnPhasesExpiry {
+		learned = learnedPhases{}
+	}
+
+	lp.Lock()
+	lp.learnedPhases = learned
+	lp.Unlock()
+}
+
+// learnVehiclePhases updates the active vehicle's learned phases from the phases measured while charging.
+// Measured phases must be stable for several cycles after a phase switch and the vehicle must draw
+// the commanded current, excluding e.g. end of charge taper or battery balancing.
+func (lp *LoadPoint) learnVehiclePhases(measured int, current float64) {
+	if lp.vehicle == nil {
+		return
+	}
+
+	lp.Lock()
+	commanded := current >= lp.chargeCurrent-learnPhasesTolerance
+	if lp.observedPhases != measured || !commanded {
+		lp.observedPhases = measured
+		lp.observedPhasesCount = 0
+	}
+	if commanded {
+		lp.observedPhasesCount++
+	}
+	stable := lp.observedPhasesCount >= learnPhasesCycles
+	lp.Unlock()
+
+	if !stable {
+		return
+	}
+
+	learned := lp.getLearnedPhases()
+	prev := learned
+
+	switch physical := lp.GetPhases(); {
+	case physical == 3:
+		learned.Phases = measured
+	case physical == 1:
+		// vehicle keeps charging on multiple phases after switching to 1p
+		learned.Ignore1p = measured > 1
+	}
+
+	now := lp.clock.Now()
+	learned.Updated = now
+
+	changed := learned.Phases != prev.Phases || learned.Ignore1p != prev.Ignore1p
+	if changed {
+		lp.log.INFO.Printf("learned vehicle phases: %dp (ignore 1p: %t)", learned.Phases, learned.Ignore1p)
+	}
+
+	lp.Lock()
+	lp.learnedPhases = learned
+	lp.Unlock()
+
+	// persist changes and confirmations once per refresh interval
+	if !changed && now.Sub(prev.Updated) < learnPhasesRefresh {
+		return
+	}
+
+	if err := store.Instance.Set(learnedPhasesKey(lp.vehicle), learned); err != nil {
+		lp.log.ERROR.Printf("learned phases: %v", err)
+	}
+}
+
+// phaseSwitchDelay returns the pv mode delay before switching to phases
+func (lp *LoadPoint) phaseSwitchDelay(phases int) time.Duration {
+	if phases == 1 {
+		if lp.PhaseSwitch.Scale1pDelay > 0 {
+			return lp.PhaseSwitch.Scale1pDelay
+		}
+		return lp.Disable.Delay
+	}
+
+	if lp.PhaseSwitch.Scale3pDelay > 0 {
+		return lp.PhaseSwitch.Scale3pDelay
+	}
+	return lp.Enable.Delay
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/evcc-io/evcc/api"
 	"github.com/evcc-io/evcc/mock"
 	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/store"
 	"github.com/golang/mock/gomock"
 )
 
@@ -116,6 +117,7 @@ func TestPvScalePhases(t *testing.T) {
 
 		vehicle := mock.NewMockVehicle(ctrl)
 		vehicle.EXPECT().Phases().Return(tc.vehicle).MinTimes(1)
+		vehicle.EXPECT().Title().Return("foo").AnyTimes()
 
 		lp := &LoadPoint{
 			log:         util.NewLogger("foo"),
@@ -285,6 +287,7 @@ func TestPvScalePhasesTimer(t *testing.T) {
 
 		lp := &LoadPoint{
 			log:            util.NewLogger("foo"),
+			bus:            evbus.New(),
 			clock:          clock,
 			charger:        charger,
 			MinCurrent:     minA,
@@ -317,3 +320,125 @@ func TestPvScalePhasesTimer(t *testing.T) {
 		}
 	}
 }
+
+func TestLearnVehiclePhases(t *testing.T) {
+	store.Instance = store.New()
+
+	ctrl := gomock.NewController(t)
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Title().Return("foo").AnyTimes()
+	vehicle.EXPECT().Phases().Return(3).AnyTimes()
+
+	clck := clock.NewMock()
+	lp := &LoadPoint{
+		log:           util.NewLogger("foo"),
+		clock:         clck,
+		vehicle:       vehicle,
+		Phases:        3,
+		chargeCurrent: 16,
+	}
+
+	learnCurrent := func(phases, cycles int, current float64) {
+		for i := 0; i < cycles; i++ {
+			lp.measuredPhases = phases
+			lp.learnVehiclePhases(phases, current)
+		}
+	}
+
+	learn := func(phases, cycles int) {
+		learnCurrent(phases, cycles, 16)
+	}
+
+	// end of charge taper on 1p below commanded current
+	learnCurrent(1, 2*learnPhasesCycles, 5)
+	if learned := lp.getLearnedPhases(); learned.Phases != 0 {
+		t.Errorf("unexpected learned phases during taper: %+v", learned)
+	}
+
+	// 2p vehicle on 3p charger, ramping up after start
+	learn(1, learnPhasesCycles-1)
+	learn(2, learnPhasesCycles-1)
+	if phs := lp.getVehiclePhases(); phs != 3 {
+		t.Errorf("unexpected learned phases before stable, got %dp", phs)
+	}
+
+	learn(2, 1)
+	if phs := lp.getVehiclePhases(); phs != 2 {
+		t.Errorf("expected learned 2p, got %dp", phs)
+	}
+
+	// vehicle still charging 2p right after switching to 1p
+	lp.Phases = 1
+	lp.resetMeasuredPhases()
+	learn(2, learnPhasesCycles-1)
+	if lp.getLearnedPhases().Ignore1p {
+		t.Error("unexpected ignore 1p before stable")
+	}
+
+	// vehicle keeps charging 2p after switching to 1p
+	learn(2, 1)
+	if phs := lp.activePhases(); phs != 2 {
+		t.Errorf("expected active 2p, got %dp", phs)
+	}
+
+	// restored for vehicle
+	lp.loadLearnedPhases(nil)
+	lp.loadLearnedPhases(vehicle)
+
+	if learned := lp.getLearnedPhases(); learned.Phases != 2 || !learned.Ignore1p {
+		t.Errorf("unexpected learned phases: %+v", learned)
+	}
+
+	// must not scale down
+	lp.Phases = 3
+	if lp.pvScalePhases(0, minA, maxA) || !lp.phaseTimer.IsZero() {
+		t.Error("unexpected phase switch")
+	}
+
+	// vehicle charging 1p after switching to 1p
+	lp.Phases = 1
+	lp.resetMeasuredPhases()
+	learn(1, learnPhasesCycles)
+	if lp.getLearnedPhases().Ignore1p {
+		t.Error("expected ignore 1p cleared")
+	}
+
+	// learned 1p does not block scaling up
+	lp.resetMeasuredPhases()
+	lp.Phases = 3
+	learn(1, learnPhasesCycles)
+	lp.resetMeasuredPhases()
+	if learned := lp.getLearnedPhases(); learned.Phases != 1 || lp.maxActivePhases() != 3 {
+		t.Errorf("expected scalable 3p with learned 1p, got %dp: %+v", lp.maxActivePhases(), learned)
+	}
+
+	// expired
+	clck.Add(learnPhasesExpiry + time.Minute)
+	lp.loadLearnedPhases(vehicle)
+	if learned := lp.getLearnedPhases(); learned.Phases != 0 || learned.Ignore1p {
+		t.Errorf("expected expired learned phases: %+v", learned)
+	}
+}
+
+func TestPhaseSwitchDelay(t *testing.T) {
+	lp := &LoadPoint{
+		Enable:  ThresholdConfig{Delay: time.Minute},
+		Disable: ThresholdConfig{Delay: 3 * time.Minute},
+	}
+
+	if d := lp.phaseSwitchDelay(1); d != 3*time.Minute {
+		t.Errorf("expected disable delay, got %v", d)
+	}
+	if d := lp.phaseSwitchDelay(3); d != time.Minute {
+		t.Errorf("expected enable delay, got %v", d)
+	}
+
+	lp.PhaseSwitch = PhaseSwitchConfig{Scale1pDelay: 10 * time.Minute, Scale3pDelay: 5 * time.Minute}
+
+	if d := lp.phaseSwitchDelay(1); d != 10*time.Minute {
+		t.Errorf("expected 1p delay, got %v", d)
+	}
+	if d := lp.phaseSwitchDelay(3); d != 5*time.Minute {
+		t.Errorf("expected 3p delay, got %v", d)
+	}
+}
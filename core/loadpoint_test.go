@@ -377,6 +377,7 @@ func TestDisableAndEnableAtTargetSoC(t *testing.T) {
 	// wrap vehicle with estimator
 	vehicle.EXPECT().Capacity().Return(int64(10))
 	vehicle.EXPECT().Phases().Return(0).AnyTimes()
+	vehicle.EXPECT().Title().Return("foo").AnyTimes()
 	socEstimator := soc.NewEstimator(util.NewLogger("foo"), charger, vehicle, false)
 
 	lp := &LoadPoint{
@@ -821,3 +822,34 @@ func TestVehicleDetectByID(t *testing.T) {
 		}
 	}
 }
+
+func TestPushEventVehicle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Title().Return("target")
+
+	pushChan := make(chan push.Event, 2)
+
+	lp := &LoadPoint{
+		log:       util.NewLogger("foo"),
+		pushChan:  pushChan,
+		vehicle:   vehicle,
+		vehicleID: "abc",
+	}
+
+	lp.pushEvent(evVehicleDisconnect, nil)
+
+	// vehicle removed before event is routed
+	lp.vehicle = nil
+	lp.vehicleID = ""
+	lp.pushEvent(evChargeStop, map[string]interface{}{"foo": "bar"})
+
+	if ev := <-pushChan; ev.Attributes["vehicleTitle"] != "target" || ev.Attributes["vehicleIdentity"] != "abc" {
+		t.Errorf("expected vehicle attributes, got %v", ev.Attributes)
+	}
+
+	if ev := <-pushChan; ev.Attributes["vehicleTitle"] != "" || ev.Attributes["vehicleIdentity"] != "" || ev.Attributes["foo"] != "bar" {
+		t.Errorf("expected no vehicle attributes, got %v", ev.Attributes)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"github.com/avast/retry-go/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Device types used as metric labels
+const (
+	deviceMeter   = "meter"
+	deviceCharger = "charger"
+	deviceVehicle = "vehicle"
+)
+
+var (
+	deviceErrorMetric *prometheus.CounterVec
+	deviceRetryMetric *prometheus.CounterVec
+)
+
+func init() {
+	labels := []string{"device", "name"}
+
+	deviceErrorMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "evcc",
+		Subsystem: "device",
+		Name:      "errors_total",
+		Help:      "Total count of failed device reads",
+	}, labels)
+
+	deviceRetryMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "evcc",
+		Subsystem: "device",
+		Name:      "retries_total",
+		Help:      "Total count of retried device reads",
+	}, labels)
+
+	prometheus.MustRegister(deviceErrorMetric, deviceRetryMetric)
+}
+
+// deviceRetryOptions returns the default retry options counting retries of the given device
+func deviceRetryOptions(device, name string) []retry.Option {
+	return append(append([]retry.Option{}, retryOptions...), retry.OnRetry(func(uint, error) {
+		deviceRetryMetric.WithLabelValues(device, name).Inc()
+	}))
+}
+
+// deviceError counts a failed device read
+func deviceError(device, name string) {
+	deviceErrorMetric.WithLabelValues(device, name).Inc()
+}
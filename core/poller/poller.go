@@ -0,0 +1,411 @@
+package poller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/devices"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/request"
+)
+
+const (
+	// Interval is the default minimum interval between api requests of the same account
+	Interval = time.Minute
+
+	// burst is the period during which all reads are considered a single request round
+	burst = 10 * time.Second
+
+	// maxBackoff is the maximum backoff duration after the api rejected requests
+	maxBackoff = time.Hour
+)
+
+// Value keys
+const (
+	SoC        = "SoC"
+	Range      = "Range"
+	Odometer   = "Odometer"
+	Climater   = "Climater"
+	FinishTime = "FinishTime"
+	Status     = "Status"
+)
+
+// Instance is the central vehicle poller
+var Instance = New(Interval)
+
+// Value is a cached vehicle api result
+type Value struct {
+	Val     interface{}
+	Updated time.Time
+}
+
+// limiter tracks request rounds and backoff of a single account
+type limiter struct {
+	interval time.Duration
+	round    time.Time // start of current request round
+	backoff  time.Duration
+	until    time.Time // no requests before
+}
+
+// allowed checks if a request may be sent and starts a new request round if required
+func (l *limiter) allowed(now time.Time) bool {
+	if now.Before(l.until) {
+		return false
+	}
+
+	if now.Sub(l.round) < burst {
+		return true
+	}
+
+	if now.Sub(l.round) >= l.interval {
+		l.round = now
+		return true
+	}
+
+	return false
+}
+
+// Poller owns all vehicle api reads. It caches results, shares rate limits across
+// all vehicles of the same account and backs off when the api rejects requests.
+type Poller struct {
+	mu       sync.Mutex
+	log      *util.Logger
+	clock    clock.Clock
+	interval time.Duration
+	limits   map[string]time.Duration         // per-account interval overrides
+	accounts map[api.Vehicle]string           // vehicle to account mapping
+	limiters map[string]*limiter              // account rate limits
+	values   map[api.Vehicle]map[string]Value // cached results
+}
+
+// New creates a vehicle poller
+func New(interval time.Duration) *Poller {
+	return &Poller{
+		log:      util.NewLogger("poller"),
+		clock:    clock.New(),
+		interval: interval,
+		limits:   make(map[string]time.Duration),
+		accounts: make(map[api.Vehicle]string),
+		limiters: make(map[string]*limiter),
+		values:   make(map[api.Vehicle]map[string]Value),
+	}
+}
+
+// Configure sets the default request interval and per-account overrides
+func (p *Poller) Configure(interval time.Duration, limits map[string]time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if interval > 0 {
+		p.interval = interval
+	}
+
+	for account, limit := range limits {
+		p.limits[account] = limit
+	}
+}
+
+// Register assigns the vehicle to an account in brand/user notation. Vehicles of the same
+// account share rate limits. Unregistered vehicles are rate limited individually.
+func (p *Poller) Register(vehicle api.Vehicle, account string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.accounts[vehicle] = account
+}
+
+// Unregister removes the vehicle's account and cached values
+func (p *Poller) Unregister(vehicle api.Vehicle) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.accounts, vehicle)
+	delete(p.values, vehicle)
+}
+
+// limiter returns the vehicle's account limiter. Must be called with lock held.
+func (p *Poller) limiter(vehicle api.Vehicle) *limiter {
+	account, ok := p.accounts[vehicle]
+	if !ok {
+		account = vehicle.Title()
+	}
+
+	l, ok := p.limiters[account]
+	if !ok {
+		interval := p.interval
+
+		// account or brand specific limit
+		brand := strings.SplitN(account, "/", 2)[0]
+		if limit, ok := p.limits[account]; ok {
+			interval = limit
+		} else if limit, ok := p.limits[brand]; ok {
+			interval = limit
+		}
+
+		l = &limiter{interval: interval}
+		p.limiters[account] = l
+	}
+
+	return l
+}
+
+// Reset invalidates the vehicle's cached values such that the next read is served by the api if allowed
+func (p *Poller) Reset(vehicle api.Vehicle) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l := p.limiter(vehicle)
+	if !p.clock.Now().Before(l.until) {
+		l.round = time.Time{}
+	}
+}
+
+// Values returns a copy of the vehicle's cached values
+func (p *Poller) Values(vehicle api.Vehicle) map[string]Value {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := make(map[string]Value, len(p.values[vehicle]))
+	for k, v := range p.values[vehicle] {
+		res[k] = v
+	}
+
+	return res
+}
+
+// Age returns the age of the vehicle's cached value or -1 if not available
+func (p *Poller) Age(vehicle api.Vehicle, key string) time.Duration {
+	if p == nil {
+		return -1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if v, ok := p.values[vehicle][key]; ok {
+		return p.clock.Since(v.Updated)
+	}
+
+	return -1
+}
+
+// get reads the value from cache or api
+func (p *Poller) get(vehicle api.Vehicle, key string, fun func() (interface{}, error)) (interface{}, error) {
+	p.mu.Lock()
+	l := p.limiter(vehicle)
+	cached, ok := p.values[vehicle][key]
+
+	if !l.allowed(p.clock.Now()) {
+		p.mu.Unlock()
+
+		if ok {
+			return cached.Val, nil
+		}
+		return nil, api.ErrMustRetry
+	}
+	p.mu.Unlock()
+
+	var val interface{}
+	err := devices.Instance.Measure(vehicle, func() (err error) {
+		val, err = fun()
+		return err
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		var se request.StatusError
+		if errors.As(err, &se) && se.HasStatus(http.StatusTooManyRequests) {
+			if l.backoff = 2 * l.backoff; l.backoff == 0 {
+				l.backoff = l.interval
+			}
+			if l.backoff > maxBackoff {
+				l.backoff = maxBackoff
+			}
+
+			l.until = p.clock.Now().Add(l.backoff)
+			p.log.WARN.Printf("%s: rate limited, backing off for %v", vehicle.Title(), l.backoff)
+
+			if ok {
+				return cached.Val, nil
+			}
+		}
+
+		return nil, err
+	}
+
+	l.backoff = 0
+
+	if _, ok := p.values[vehicle]; !ok {
+		p.values[vehicle] = make(map[string]Value)
+	}
+	p.values[vehicle][key] = Value{Val: val, Updated: p.clock.Now()}
+
+	return val, nil
+}
+
+// SoC reads the vehicle's soc
+func (p *Poller) SoC(vehicle api.Vehicle) (float64, error) {
+	if p == nil {
+		return vehicle.SoC()
+	}
+
+	res, err := p.get(vehicle, SoC, func() (interface{}, error) {
+		return vehicle.SoC()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(float64), nil
+}
+
+// Range reads the vehicle's range
+func (p *Poller) Range(vehicle api.Vehicle) (int64, error) {
+	vr, ok := vehicle.(api.VehicleRange)
+	if !ok {
+		return 0, api.ErrNotAvailable
+	}
+
+	if p == nil {
+		return vr.Range()
+	}
+
+	res, err := p.get(vehicle, Range, func() (interface{}, error) {
+		return vr.Range()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(int64), nil
+}
+
+// Odometer reads the vehicle's odometer
+func (p *Poller) Odometer(vehicle api.Vehicle) (float64, error) {
+	vo, ok := vehicle.(api.VehicleOdometer)
+	if !ok {
+		return 0, api.ErrNotAvailable
+	}
+
+	if p == nil {
+		return vo.Odometer()
+	}
+
+	res, err := p.get(vehicle, Odometer, func() (interface{}, error) {
+		return vo.Odometer()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(float64), nil
+}
+
+// FinishTime reads the vehicle's charge finish time
+func (p *Poller) FinishTime(vehicle api.Vehicle) (time.Time, error) {
+	vf, ok := vehicle.(api.VehicleFinishTimer)
+	if !ok {
+		return time.Time{}, api.ErrNotAvailable
+	}
+
+	if p == nil {
+		return vf.FinishTime()
+	}
+
+	res, err := p.get(vehicle, FinishTime, func() (interface{}, error) {
+		return vf.FinishTime()
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return res.(time.Time), nil
+}
+
+// Status reads the vehicle's charge status
+func (p *Poller) Status(vehicle api.Vehicle) (api.ChargeStatus, error) {
+	vs, ok := vehicle.(api.ChargeState)
+	if !ok {
+		return api.StatusNone, api.ErrNotAvailable
+	}
+
+	if p == nil {
+		return vs.Status()
+	}
+
+	res, err := p.get(vehicle, Status, func() (interface{}, error) {
+		return vs.Status()
+	})
+	if err != nil {
+		return api.StatusNone, err
+	}
+
+	return res.(api.ChargeStatus), nil
+}
+
+type climater struct {
+	active                  bool
+	outsideTemp, targetTemp float64
+}
+
+// Climater reads the vehicle's climatisation state
+func (p *Poller) Climater(vehicle api.Vehicle) (bool, float64, float64, error) {
+	vc, ok := vehicle.(api.VehicleClimater)
+	if !ok {
+		return false, 0, 0, api.ErrNotAvailable
+	}
+
+	if p == nil {
+		return vc.Climater()
+	}
+
+	res, err := p.get(vehicle, Climater, func() (interface{}, error) {
+		active, outsideTemp, targetTemp, err := vc.Climater()
+		return climater{active, outsideTemp, targetTemp}, err
+	})
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	c := res.(climater)
+	return c.active, c.outsideTemp, c.targetTemp, nil
+}
+
+// vehicle is an api.Vehicle whose soc and finish time are read through the poller
+type vehicle struct {
+	api.Vehicle
+	poller *Poller
+}
+
+// Vehicle wraps the vehicle such that soc and finish time are read through the poller
+func (p *Poller) Vehicle(v api.Vehicle) api.Vehicle {
+	if p == nil {
+		return v
+	}
+	return &vehicle{Vehicle: v, poller: p}
+}
+
+// SoC implements the api.Vehicle interface
+func (v *vehicle) SoC() (float64, error) {
+	return v.poller.SoC(v.Vehicle)
+}
+
+// FinishTime implements the api.VehicleFinishTimer interface
+func (v *vehicle) FinishTime() (time.Time, error) {
+	return v.poller.FinishTime(v.Vehicle)
+}
@@ -0,0 +1,138 @@
+package poller
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/util/request"
+)
+
+type stubVehicle struct {
+	api.Vehicle
+	title string
+	soc   float64
+	err   error
+	calls int
+}
+
+func (v *stubVehicle) Title() string {
+	return v.title
+}
+
+func (v *stubVehicle) SoC() (float64, error) {
+	v.calls++
+	return v.soc, v.err
+}
+
+func newPoller(interval time.Duration) (*Poller, *clock.Mock) {
+	clck := clock.NewMock()
+	p := New(interval)
+	p.clock = clck
+	return p, clck
+}
+
+func TestPollerRateLimit(t *testing.T) {
+	p, clck := newPoller(time.Minute)
+	v := &stubVehicle{title: "v", soc: 50}
+
+	if soc, err := p.SoC(v); soc != 50 || err != nil || v.calls != 1 {
+		t.Fatalf("unexpected soc %.0f, err %v, calls %d", soc, err, v.calls)
+	}
+
+	// burst
+	clck.Add(5 * time.Second)
+	if _, _ = p.SoC(v); v.calls != 2 {
+		t.Errorf("expected burst request, got %d calls", v.calls)
+	}
+
+	// limited, served from cache
+	v.soc = 60
+	clck.Add(30 * time.Second)
+	if soc, err := p.SoC(v); soc != 50 || err != nil || v.calls != 2 {
+		t.Errorf("expected cached soc, got %.0f, err %v, calls %d", soc, err, v.calls)
+	}
+
+	if age := p.Age(v, SoC); age != 30*time.Second {
+		t.Errorf("unexpected age %v", age)
+	}
+
+	// next round
+	clck.Add(30 * time.Second)
+	if soc, _ := p.SoC(v); soc != 60 || v.calls != 3 {
+		t.Errorf("expected updated soc, got %.0f, calls %d", soc, v.calls)
+	}
+}
+
+func TestPollerSharedAccount(t *testing.T) {
+	p, clck := newPoller(time.Minute)
+	p.Configure(0, map[string]time.Duration{"brand": 10 * time.Minute})
+
+	v1 := &stubVehicle{title: "v1"}
+	v2 := &stubVehicle{title: "v2"}
+	p.Register(v1, "brand/user")
+	p.Register(v2, "brand/user")
+
+	_, _ = p.SoC(v1)
+	clck.Add(time.Minute)
+
+	// second vehicle of same account has no cached value
+	if _, err := p.SoC(v2); !errors.Is(err, api.ErrMustRetry) || v2.calls != 0 {
+		t.Errorf("expected shared limit, got err %v, calls %d", err, v2.calls)
+	}
+
+	clck.Add(9 * time.Minute)
+	if _, err := p.SoC(v2); err != nil || v2.calls != 1 {
+		t.Errorf("expected request, got err %v, calls %d", err, v2.calls)
+	}
+
+	// reset starts a new round
+	clck.Add(time.Minute)
+	p.Reset(v1)
+	if _, _ = p.SoC(v1); v1.calls != 2 {
+		t.Errorf("expected request after reset, got %d calls", v1.calls)
+	}
+}
+
+func TestPollerBackoff(t *testing.T) {
+	p, clck := newPoller(time.Minute)
+	v := &stubVehicle{title: "v", soc: 50}
+
+	_, _ = p.SoC(v)
+
+	v.err = request.NewStatusError(&http.Response{StatusCode: http.StatusTooManyRequests})
+
+	for _, backoff := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		p.Reset(v)
+
+		if soc, err := p.SoC(v); soc != 50 || err != nil {
+			t.Errorf("expected cached soc, got %.0f, err %v", soc, err)
+		}
+
+		calls := v.calls
+		clck.Add(backoff - time.Second)
+		p.Reset(v)
+
+		if _, _ = p.SoC(v); v.calls != calls {
+			t.Errorf("expected backoff %v", backoff)
+		}
+
+		clck.Add(time.Second)
+	}
+
+	// success resets backoff
+	v.err = nil
+	p.Reset(v)
+	if soc, err := p.SoC(v); soc != 50 || err != nil {
+		t.Errorf("unexpected soc %.0f, err %v", soc, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l := p.limiter(v); l.backoff != 0 {
+		t.Errorf("expected backoff reset, got %v", l.backoff)
+	}
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+)
+
+const preconditionRetry = 5 * time.Minute
+
+// PreconditionConfig defines if and when the vehicle climatisation is started before target charge departure
+type PreconditionConfig struct {
+	Duration    time.Duration `mapstructure:"duration"`    // start climatisation this long before departure
+	Temperature float64       `mapstructure:"temperature"` // target temperature in °C, vehicle default if zero
+}
+
+// preconditioner starts the vehicle climatisation before departure while the vehicle is still connected
+type preconditioner struct {
+	clock       clock.Clock
+	duration    time.Duration
+	temperature float64
+	departure   time.Time // target charge time
+	active      bool      // climatisation started
+	failed      time.Time // last failed command timestamp
+}
+
+// newPreconditioner creates preconditioner from config. It returns nil if preconditioning is disabled.
+func newPreconditioner(clock clock.Clock, cc PreconditionConfig) *preconditioner {
+	if cc.Duration <= 0 {
+		return nil
+	}
+
+	return &preconditioner{
+		clock:       clock,
+		duration:    cc.Duration,
+		temperature: cc.Temperature,
+	}
+}
+
+// setDeparture sets the departure time, zero removes it
+func (p *preconditioner) setDeparture(departure time.Time) {
+	if p != nil {
+		p.departure = departure
+	}
+}
+
+// running returns true if climatisation has been started
+func (p *preconditioner) running() bool {
+	return p != nil && p.active
+}
+
+// update starts or stops the vehicle climatisation depending on departure.
+// It returns true if a command was sent.
+func (p *preconditioner) update(vehicle api.Vehicle, connected bool) (bool, error) {
+	vc, ok := vehicle.(api.VehicleClimateControl)
+	if p == nil || !ok || p.departure.IsZero() && !p.active {
+		return false, nil
+	}
+
+	now := p.clock.Now()
+
+	// departed, vehicle keeps climatisation running on its own
+	if !p.departure.IsZero() && !now.Before(p.departure) {
+		p.departure = time.Time{}
+		p.active = false
+		return false, nil
+	}
+
+	due := connected && !p.departure.IsZero() && !now.Before(p.departure.Add(-p.duration))
+	if due == p.active || p.clock.Since(p.failed) < preconditionRetry {
+		return false, nil
+	}
+
+	var err error
+	if due {
+		err = vc.StartClimate(p.temperature)
+	} else {
+		err = vc.StopClimate()
+	}
+
+	if err != nil {
+		p.failed = now
+		return true, err
+	}
+
+	p.active = due
+
+	return true, nil
+}
+
+// reset clears the departure, e.g. when the vehicle is disconnected
+func (p *preconditioner) reset() {
+	if p != nil {
+		p.departure = time.Time{}
+		p.active = false
+		p.failed = time.Time{}
+	}
+}
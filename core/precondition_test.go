@@ -0,0 +1,117 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+)
+
+type climateVehicle struct {
+	api.Vehicle
+	started, stopped int
+	temp             float64
+	err              error
+}
+
+func (v *climateVehicle) StartClimate(targetTemp float64) error {
+	if v.err != nil {
+		return v.err
+	}
+	v.started++
+	v.temp = targetTemp
+	return nil
+}
+
+func (v *climateVehicle) StopClimate() error {
+	v.stopped++
+	return nil
+}
+
+func TestPreconditioner(t *testing.T) {
+	clck := clock.NewMock()
+	v := new(climateVehicle)
+
+	if newPreconditioner(clck, PreconditionConfig{}) != nil {
+		t.Error("expected disabled preconditioner")
+	}
+
+	p := newPreconditioner(clck, PreconditionConfig{Duration: 30 * time.Minute, Temperature: 21})
+	p.setDeparture(clck.Now().Add(time.Hour))
+
+	// before preconditioning window
+	if sent, _ := p.update(v, true); sent || p.running() {
+		t.Error("unexpected start before window")
+	}
+
+	// vehicle without climate control
+	clck.Add(45 * time.Minute)
+	if sent, _ := p.update(&struct{ api.Vehicle }{}, true); sent {
+		t.Error("unexpected command for unsupported vehicle")
+	}
+
+	// not connected
+	if sent, _ := p.update(v, false); sent {
+		t.Error("unexpected start while disconnected")
+	}
+
+	if sent, err := p.update(v, true); !sent || err != nil || !p.running() || v.started != 1 || v.temp != 21 {
+		t.Errorf("expected start, got sent %v err %v started %d temp %.0f", sent, err, v.started, v.temp)
+	}
+
+	// already started
+	if sent, _ := p.update(v, true); sent || v.started != 1 {
+		t.Error("unexpected repeated start")
+	}
+
+	// departure removed
+	p.setDeparture(time.Time{})
+	if sent, _ := p.update(v, true); !sent || p.running() || v.stopped != 1 {
+		t.Errorf("expected stop, got sent %v stopped %d", sent, v.stopped)
+	}
+}
+
+func TestPreconditionerDeparture(t *testing.T) {
+	clck := clock.NewMock()
+	v := new(climateVehicle)
+
+	p := newPreconditioner(clck, PreconditionConfig{Duration: 30 * time.Minute})
+	p.setDeparture(clck.Now().Add(10 * time.Minute))
+
+	if sent, _ := p.update(v, true); !sent || !p.running() {
+		t.Error("expected start")
+	}
+
+	// vehicle keeps climatisation running after departure
+	clck.Add(10 * time.Minute)
+	if sent, _ := p.update(v, true); sent || p.running() || v.stopped != 0 {
+		t.Errorf("unexpected command after departure, stopped %d", v.stopped)
+	}
+}
+
+func TestPreconditionerRetry(t *testing.T) {
+	clck := clock.NewMock()
+	v := &climateVehicle{err: errors.New("foo")}
+
+	p := newPreconditioner(clck, PreconditionConfig{Duration: time.Hour})
+	p.setDeparture(clck.Now().Add(30 * time.Minute))
+
+	if _, err := p.update(v, true); err == nil || p.running() {
+		t.Error("expected error")
+	}
+
+	v.err = nil
+
+	// rate limited after failure
+	clck.Add(time.Minute)
+	if sent, _ := p.update(v, true); sent {
+		t.Error("unexpected retry")
+	}
+
+	clck.Add(preconditionRetry)
+	if sent, _ := p.update(v, true); !sent || !p.running() {
+		t.Error("expected retry")
+	}
+}
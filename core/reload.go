@@ -0,0 +1,254 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/core/wrapper"
+	"github.com/evcc-io/evcc/tariff"
+)
+
+// ErrRestartRequired indicates configuration changes that cannot be applied to the running site
+var ErrRestartRequired = errors.New("restart required")
+
+// LoadPointSettings are the loadpoint settings that can be changed without restart
+type LoadPointSettings struct {
+	Mode            api.ChargeMode
+	Enable, Disable ThresholdConfig
+	PhaseSwitch     PhaseSwitchConfig
+}
+
+// Reconfigure executes fun within the site's control loop such that devices can be
+// replaced without racing loadpoint updates. It blocks until fun has been executed.
+func (site *Site) Reconfigure(fun func() error) error {
+	errC := make(chan error)
+	site.reconfigureC <- func() { errC <- fun() }
+	return <-errC
+}
+
+// CanReplaceMeter validates that the new meter provides all capabilities used by the site
+func (site *Site) CanReplaceMeter(old, new api.Meter) error {
+	for _, meter := range site.batteryMeters {
+		if _, ok := new.(api.Battery); !ok && meter == old {
+			return errors.New("battery meter must provide soc")
+		}
+	}
+
+	return nil
+}
+
+// ReplaceMeter replaces all references to the old meter. Must be called from Reconfigure.
+func (site *Site) ReplaceMeter(old, new api.Meter) error {
+	if err := site.CanReplaceMeter(old, new); err != nil {
+		return err
+	}
+
+	for id, meter := range site.batteryMeters {
+		if meter == old {
+			site.batteryMeters[id] = new
+		}
+	}
+
+	if site.gridMeter == old {
+		site.gridMeter = new
+	}
+
+	for id, meter := range site.pvMeters {
+		if meter == old {
+			site.pvMeters[id] = new
+		}
+	}
+
+	for _, lp := range site.loadpoints {
+		lp.replaceMeter(old, new)
+	}
+
+	return nil
+}
+
+// CanReplaceCharger validates that the new charger provides all capabilities used by the loadpoints
+func (site *Site) CanReplaceCharger(old, new api.Charger) error {
+	for _, lp := range site.loadpoints {
+		if err := lp.canReplaceCharger(old, new); err != nil {
+			return fmt.Errorf("%s: %w", lp.Title, err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceCharger replaces all references to the old charger. Must be called from Reconfigure.
+func (site *Site) ReplaceCharger(old, new api.Charger) error {
+	// validate before replacing
+	if err := site.CanReplaceCharger(old, new); err != nil {
+		return err
+	}
+
+	for _, lp := range site.loadpoints {
+		lp.replaceCharger(old, new)
+	}
+
+	return nil
+}
+
+// ReplaceVehicle replaces all references to the old vehicle. Must be called from Reconfigure.
+func (site *Site) ReplaceVehicle(old, new api.Vehicle) {
+	for _, lp := range site.loadpoints {
+		lp.replaceVehicle(old, new)
+	}
+}
+
+// ReconfigureLoadPoint applies the loadpoint settings. Must be called from Reconfigure.
+func (site *Site) ReconfigureLoadPoint(id int, cc LoadPointSettings) error {
+	if id < 0 || id >= len(site.loadpoints) {
+		return fmt.Errorf("invalid loadpoint: %d", id+1)
+	}
+
+	site.loadpoints[id].reconfigure(cc)
+
+	return nil
+}
+
+// SetTariffs replaces the site's tariffs. Must be called from Reconfigure.
+func (site *Site) SetTariffs(tariffs tariff.Tariffs) {
+	site.Lock()
+	site.tariffs = tariffs
+	site.Unlock()
+
+	// savings are only accessed from the control loop
+	site.savings.tariffs = tariffs
+	site.publish("currency", tariffs.Currency.String())
+}
+
+// replaceMeter replaces the charge meter and keeps the session's charged energy
+func (lp *LoadPoint) replaceMeter(old, new api.Meter) {
+	if lp.chargeMeter != old {
+		return
+	}
+
+	lp.log.INFO.Println("charge meter replaced")
+	lp.chargeMeter = new
+
+	if rt, ok := lp.chargeRater.(*wrapper.ChargeRater); ok {
+		rt.SetMeter(new)
+	}
+}
+
+// canReplaceCharger validates that the new charger provides all capabilities used by the loadpoint
+func (lp *LoadPoint) canReplaceCharger(old, new api.Charger) error {
+	if lp.charger != old {
+		return nil
+	}
+
+	if _, ok := new.(api.Meter); !ok && interface{}(lp.chargeMeter) == interface{}(old) {
+		return fmt.Errorf("charger must provide power: %w", ErrRestartRequired)
+	}
+
+	if _, ok := new.(api.ChargeRater); !ok && interface{}(lp.chargeRater) == interface{}(old) {
+		return fmt.Errorf("charger must provide charged energy: %w", ErrRestartRequired)
+	}
+
+	if _, ok := new.(api.ChargeTimer); !ok && interface{}(lp.chargeTimer) == interface{}(old) {
+		return fmt.Errorf("charger must provide charge duration: %w", ErrRestartRequired)
+	}
+
+	_, oldPhases := old.(api.ChargePhases)
+	if _, ok := new.(api.ChargePhases); ok != oldPhases {
+		return fmt.Errorf("phase switching changed: %w", ErrRestartRequired)
+	}
+
+	if _, ok := new.(api.Identifier); !ok && lp.users != nil {
+		return errors.New("charger must provide identification for users")
+	}
+
+	return nil
+}
+
+// replaceCharger replaces the charger and all roles it has been used for.
+// The current is re-applied on next update.
+func (lp *LoadPoint) replaceCharger(old, new api.Charger) {
+	if lp.charger != old {
+		return
+	}
+
+	lp.Lock()
+
+	lp.log.INFO.Println("charger replaced")
+	lp.charger = new
+
+	if interface{}(lp.chargeMeter) == interface{}(old) {
+		lp.chargeMeter = new.(api.Meter)
+
+		if rt, ok := lp.chargeRater.(*wrapper.ChargeRater); ok {
+			rt.SetMeter(lp.chargeMeter)
+		}
+	}
+
+	if interface{}(lp.chargeRater) == interface{}(old) {
+		lp.chargeRater = new.(api.ChargeRater)
+	}
+
+	if interface{}(lp.chargeTimer) == interface{}(old) {
+		lp.chargeTimer = new.(api.ChargeTimer)
+	}
+
+	// force current update
+	lp.chargeCurrent = 0
+
+	if lp.socEstimator != nil {
+		lp.socEstimator = lp.newSocEstimator(lp.vehicle)
+	}
+
+	lp.Unlock()
+
+	// allow charger to access loadpoint
+	if ctrl, ok := new.(loadpoint.Controller); ok {
+		ctrl.LoadpointControl(lp)
+	}
+}
+
+// replaceVehicle replaces the assigned and active vehicle
+func (lp *LoadPoint) replaceVehicle(old, new api.Vehicle) {
+	lp.Lock()
+	defer lp.Unlock()
+
+	for id, vehicle := range lp.vehicles {
+		if vehicle == old {
+			lp.vehicles[id] = new
+		}
+	}
+
+	if lp.vehicle != old {
+		return
+	}
+
+	lp.log.INFO.Printf("vehicle replaced: %s", new.Title())
+
+	coordinator.release(old)
+	coordinator.aquire(lp, new)
+
+	lp.vehicle = new
+	lp.vehicleCtrl.reset()
+
+	if lp.socEstimator != nil {
+		lp.socEstimator = lp.newSocEstimator(new)
+	}
+
+	lp.publish("vehicleTitle", new.Title())
+	lp.publish("vehicleCapacity", new.Capacity())
+}
+
+// reconfigure applies the loadpoint settings. A changed mode replaces the current and default mode.
+func (lp *LoadPoint) reconfigure(cc LoadPointSettings) {
+	lp.log.INFO.Println("settings reconfigured")
+
+	lp.Enable, lp.Disable = cc.Enable, cc.Disable
+	lp.PhaseSwitch = cc.PhaseSwitch
+
+	if cc.Mode != "" && lp.onDisconnect.Mode != nil && cc.Mode != *lp.onDisconnect.Mode {
+		*lp.onDisconnect.Mode = cc.Mode
+		lp.SetMode(cc.Mode)
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/mock"
+	"github.com/evcc-io/evcc/tariff"
+	"github.com/evcc-io/evcc/util"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/text/currency"
+)
+
+func TestReplaceCharger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	type chargerMeter struct {
+		*mock.MockCharger
+		*mock.MockMeter
+	}
+
+	old := &chargerMeter{mock.NewMockCharger(ctrl), mock.NewMockMeter(ctrl)}
+
+	lp := &LoadPoint{
+		log:         util.NewLogger("foo"),
+		charger:     old,
+		chargeMeter: old,
+	}
+	lp.chargeCurrent = maxA
+
+	site := &Site{loadpoints: []*LoadPoint{lp}}
+
+	// missing meter capability
+	if err := site.ReplaceCharger(old, mock.NewMockCharger(ctrl)); err == nil {
+		t.Error("expected missing meter error")
+	}
+
+	if lp.charger != old || lp.chargeCurrent != maxA {
+		t.Error("charger must not be replaced on error")
+	}
+
+	new := &chargerMeter{mock.NewMockCharger(ctrl), mock.NewMockMeter(ctrl)}
+	if err := site.ReplaceCharger(old, new); err != nil {
+		t.Fatal(err)
+	}
+
+	if lp.charger != new || lp.chargeMeter != api.Meter(new) {
+		t.Error("charger not replaced")
+	}
+
+	if lp.chargeCurrent != 0 {
+		t.Error("charge current must be re-applied")
+	}
+}
+
+func TestReplaceMeter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	old := mock.NewMockMeter(ctrl)
+	site := &Site{gridMeter: old, pvMeters: []api.Meter{old}}
+
+	new := mock.NewMockMeter(ctrl)
+	if err := site.ReplaceMeter(old, new); err != nil {
+		t.Fatal(err)
+	}
+
+	if site.gridMeter != new || site.pvMeters[0] != new {
+		t.Error("meter not replaced")
+	}
+
+	// battery meters must provide soc
+	site = &Site{batteryMeters: []api.Meter{old}}
+	if err := site.ReplaceMeter(old, new); err == nil {
+		t.Error("expected missing soc error")
+	}
+}
+
+func TestReconfigureLoadPoint(t *testing.T) {
+	lp := NewLoadPoint(util.NewLogger("foo"))
+	lp.Mode = api.ModePV
+	lp.collectDefaults()
+
+	site := &Site{loadpoints: []*LoadPoint{lp}}
+
+	if err := site.ReconfigureLoadPoint(1, LoadPointSettings{}); err == nil {
+		t.Error("expected invalid loadpoint error")
+	}
+
+	// user selected mode is kept if configured mode is unchanged
+	lp.Mode = api.ModeNow
+	cc := LoadPointSettings{Mode: api.ModePV, Disable: ThresholdConfig{Threshold: 500}}
+	if err := site.ReconfigureLoadPoint(0, cc); err != nil {
+		t.Fatal(err)
+	}
+
+	if lp.Mode != api.ModeNow || lp.Disable.Threshold != 500 {
+		t.Errorf("unexpected mode %s or threshold %.0f", lp.Mode, lp.Disable.Threshold)
+	}
+
+	// changed configured mode
+	cc.Mode = api.ModeMinPV
+	if err := site.ReconfigureLoadPoint(0, cc); err != nil {
+		t.Fatal(err)
+	}
+
+	if lp.Mode != api.ModeMinPV || *lp.onDisconnect.Mode != api.ModeMinPV {
+		t.Errorf("unexpected mode %s", lp.Mode)
+	}
+}
+
+func TestSetTariffsConcurrent(t *testing.T) {
+	site := &Site{savings: NewSavings(tariff.Tariffs{})}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = site.GetTariffs()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		site.SetTariffs(tariff.Tariffs{Currency: currency.EUR})
+	}
+	<-done
+
+	if site.GetTariffs().Currency != currency.EUR {
+		t.Error("tariffs not replaced")
+	}
+}
@@ -28,6 +28,28 @@ type Savings struct {
 	selfConsumptionCharged         float64   // Self-produced energy charged since startup (kWh)
 	selfConsumptionCost            float64   // Running total of charged self-produced energy cost (e.g. EUR)
 	lastGridPrice, lastFeedInPrice float64   // Stores the last published grid price. Needed to detect price changes (Awattar, ..)
+	lastCO2Intensity               float64   // Stores the last published grid co2 intensity
+	gridCO2                        float64   // Running total of charged grid energy co2 emissions (g)
+	avoidedCO2                     float64   // Running total of co2 emissions avoided by self consumption (g)
+
+	// state of last update for attributing savings to vehicles
+	share, co2Intensity, hours float64
+	vehicles                   map[string]*VehicleSavings
+}
+
+// VehicleSavings contains the charged energy and co2 emissions attributed to a vehicle
+type VehicleSavings struct {
+	Charged    float64 `json:"charged"`    // kWh
+	CO2        float64 `json:"co2"`        // g
+	AvoidedCO2 float64 `json:"avoidedCO2"` // g
+}
+
+// CO2PerKWh returns the vehicle's co2 emissions per charged kWh
+func (v VehicleSavings) CO2PerKWh() float64 {
+	if v.Charged == 0 {
+		return 0
+	}
+	return v.CO2 / v.Charged
 }
 
 func NewSavings(tariffs tariff.Tariffs) *Savings {
@@ -72,6 +94,19 @@ func (s *Savings) SavingsAmount() float64 {
 	return s.gridSavedCost
 }
 
+// CO2PerKWh returns the co2 emissions per charged kWh
+func (s *Savings) CO2PerKWh() float64 {
+	if s.TotalCharged() == 0 {
+		return 0
+	}
+	return s.gridCO2 / s.TotalCharged()
+}
+
+// AvoidedCO2 returns the co2 emissions avoided by self consumption in g
+func (s *Savings) AvoidedCO2() float64 {
+	return s.avoidedCO2
+}
+
 func (s *Savings) shareOfSelfProducedEnergy(gridPower, pvPower, batteryPower float64) float64 {
 	batteryDischarge := math.Max(0, batteryPower)
 	batteryCharge := math.Min(0, batteryPower) * -1
@@ -107,6 +142,16 @@ func (s *Savings) currentFeedInPrice() float64 {
 	return DefaultFeedInPrice
 }
 
+// currentCO2Intensity returns the grid co2 intensity or zero if not available
+func (s *Savings) currentCO2Intensity() float64 {
+	if s.tariffs.CO2 != nil {
+		if intensity, err := s.tariffs.CO2.CurrentIntensity(); err == nil {
+			return intensity
+		}
+	}
+	return 0
+}
+
 func (s *Savings) updatePrices(p publisher) (float64, float64) {
 	gridPrice := s.currentGridPrice()
 	if gridPrice != s.lastGridPrice {
@@ -120,6 +165,14 @@ func (s *Savings) updatePrices(p publisher) (float64, float64) {
 		p.publish("tariffFeedIn", feedinPrice)
 	}
 
+	if s.tariffs.CO2 != nil {
+		co2Intensity := s.currentCO2Intensity()
+		if co2Intensity != s.lastCO2Intensity {
+			s.lastCO2Intensity = co2Intensity
+			p.publish("tariffCO2", co2Intensity)
+		}
+	}
+
 	return gridPrice, feedinPrice
 }
 
@@ -127,14 +180,18 @@ func (s *Savings) Update(p publisher, gridPower, pvPower, batteryPower, chargePo
 	gridPrice, feedinPrice := s.updatePrices(p)
 	defer func() { s.updated = s.clock.Now() }()
 
+	s.hours = s.clock.Since(s.updated).Hours()
+	s.share = s.shareOfSelfProducedEnergy(gridPower, pvPower, batteryPower)
+	s.co2Intensity = s.currentCO2Intensity()
+
 	// no charging, no need to update
 	if chargePower == 0 {
 		return
 	}
 
 	// assume charge power as constant over the duration -> rough kWh estimate
-	energyAdded := s.clock.Since(s.updated).Hours() * chargePower / 1e3
-	share := s.shareOfSelfProducedEnergy(gridPower, pvPower, batteryPower)
+	energyAdded := s.hours * chargePower / 1e3
+	share := s.share
 
 	addedSelfConsumption := energyAdded * share
 	addedGrid := energyAdded - addedSelfConsumption
@@ -144,6 +201,8 @@ func (s *Savings) Update(p publisher, gridPower, pvPower, batteryPower, chargePo
 	s.gridSavedCost += addedSelfConsumption * (gridPrice - feedinPrice)
 	s.selfConsumptionCharged += addedSelfConsumption
 	s.selfConsumptionCost += addedSelfConsumption * feedinPrice
+	s.gridCO2 += addedGrid * s.co2Intensity
+	s.avoidedCO2 += addedSelfConsumption * s.co2Intensity
 
 	p.publish("savingsTotalCharged", s.TotalCharged())
 	p.publish("savingsGridCharged", s.gridCharged)
@@ -151,4 +210,46 @@ func (s *Savings) Update(p publisher, gridPower, pvPower, batteryPower, chargePo
 	p.publish("savingsSelfConsumptionPercent", s.SelfConsumptionPercent())
 	p.publish("savingsEffectivePrice", s.EffectivePrice())
 	p.publish("savingsAmount", s.SavingsAmount())
+
+	if s.tariffs.CO2 != nil {
+		p.publish("savingsCO2PerKWh", s.CO2PerKWh())
+		p.publish("savingsAvoidedCO2", s.AvoidedCO2())
+	}
+}
+
+// UpdateVehicle attributes the energy charged since the last Update to the given vehicle
+func (s *Savings) UpdateVehicle(p publisher, title string, chargePower float64) {
+	if chargePower == 0 {
+		return
+	}
+
+	if s.vehicles == nil {
+		s.vehicles = make(map[string]*VehicleSavings)
+	}
+
+	v, ok := s.vehicles[title]
+	if !ok {
+		v = new(VehicleSavings)
+		s.vehicles[title] = v
+	}
+
+	energyAdded := s.hours * chargePower / 1e3
+	addedSelfConsumption := energyAdded * s.share
+
+	v.Charged += energyAdded
+	v.CO2 += (energyAdded - addedSelfConsumption) * s.co2Intensity
+	v.AvoidedCO2 += addedSelfConsumption * s.co2Intensity
+
+	if s.tariffs.CO2 != nil {
+		p.publish("savingsVehicles", s.Vehicles())
+	}
+}
+
+// Vehicles returns a copy of the per-vehicle savings
+func (s *Savings) Vehicles() map[string]VehicleSavings {
+	res := make(map[string]VehicleSavings, len(s.vehicles))
+	for title, v := range s.vehicles {
+		res[title] = *v
+	}
+	return res
 }
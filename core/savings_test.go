@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/tariff"
 )
 
 func assertEnergy(t *testing.T, s *Savings, total, self, percentage float64) {
@@ -224,3 +226,40 @@ func TestEffectiveEnergyPriceAndSavingsAmount(t *testing.T) {
 		assertPrices(t, s, tc.effectivePrice, tc.savingsAmount)
 	}
 }
+
+type co2Stub float64
+
+func (c co2Stub) IsClean() (bool, error)             { return false, nil }
+func (c co2Stub) CurrentIntensity() (float64, error) { return float64(c), nil }
+func (c co2Stub) Forecast() ([]api.Rate, error)      { return nil, nil }
+
+func TestCO2PerVehicle(t *testing.T) {
+	p := StubPublisher{}
+
+	clck := clock.NewMock()
+	s := &Savings{
+		clock:   clck,
+		tariffs: tariff.Tariffs{CO2: co2Stub(400)},
+		started: clck.Now(),
+		updated: clck.Now(),
+	}
+
+	s.Update(p, 0, 0, 0, 0)
+
+	// 1 hour, 10kW, half grid, half pv
+	clck.Add(time.Hour)
+	s.Update(p, 5000, 5000, 0, 10000)
+	s.UpdateVehicle(p, "car", 10000)
+
+	if !compareWithTolerane(s.CO2PerKWh(), 200) {
+		t.Errorf("CO2PerKWh was incorrect, got: %.3f, want: %.3f.", s.CO2PerKWh(), 200.0)
+	}
+	if !compareWithTolerane(s.AvoidedCO2(), 2000) {
+		t.Errorf("AvoidedCO2 was incorrect, got: %.3f, want: %.3f.", s.AvoidedCO2(), 2000.0)
+	}
+
+	v := s.Vehicles()["car"]
+	if !compareWithTolerane(v.Charged, 10) || !compareWithTolerane(v.CO2, 2000) || !compareWithTolerane(v.AvoidedCO2, 2000) {
+		t.Errorf("vehicle savings were incorrect, got: %+v", v)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/avast/retry-go/v3"
 	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/devices"
 	"github.com/evcc-io/evcc/core/loadpoint"
 	"github.com/evcc-io/evcc/push"
 	"github.com/evcc-io/evcc/tariff"
@@ -23,7 +24,9 @@ type Updater interface {
 // Site is the main configuration container. A site can host multiple loadpoints.
 type Site struct {
 	uiChan       chan<- util.Param // client push messages
+	pushChan     chan<- push.Event // notifications
 	lpUpdateChan chan *LoadPoint
+	reconfigureC chan func() // reconfiguration requests
 
 	*Health
 
@@ -31,12 +34,16 @@ type Site struct {
 	log *util.Logger
 
 	// configuration
-	Title         string       `mapstructure:"title"`         // UI title
-	Voltage       float64      `mapstructure:"voltage"`       // Operating voltage. 230V for Germany.
-	ResidualPower float64      `mapstructure:"residualPower"` // PV meter only: household usage. Grid meter: household safety margin
-	Meters        MetersConfig // Meter references
-	PrioritySoC   float64      `mapstructure:"prioritySoC"` // prefer battery up to this SoC
-	BufferSoC     float64      `mapstructure:"bufferSoC"`   // ignore battery above this SoC
+	Title         string             `mapstructure:"title"`         // UI title
+	Voltage       float64            `mapstructure:"voltage"`       // Operating voltage. 230V for Germany.
+	ResidualPower float64            `mapstructure:"residualPower"` // PV meter only: household usage. Grid meter: household safety margin
+	Meters        MetersConfig       // Meter references
+	PrioritySoC   float64            `mapstructure:"prioritySoC"`   // prefer battery up to this SoC
+	BufferSoC     float64            `mapstructure:"bufferSoC"`     // ignore battery above this SoC
+	FeedInLimit   *FeedInLimitConfig `mapstructure:"feedInLimit"`   // grid export limitation
+	DeviceTimeout time.Duration      `mapstructure:"deviceTimeout"` // report devices failing for longer than this
+	Failsafe      FailsafeConfig     `mapstructure:"failsafe"`      // loadpoint state while grid meter is unavailable
+	Users         []UserConfig       `mapstructure:"users"`         // users allowed to charge, unrestricted if empty
 
 	// meters
 	gridMeter     api.Meter   // Grid usage meter
@@ -46,6 +53,8 @@ type Site struct {
 	tariffs    tariff.Tariffs // Tariff
 	loadpoints []*LoadPoint   // Loadpoints
 	savings    *Savings       // Savings
+	feedIn     *FeedInLimit   // Feed-in limitation
+	failsafe   *failsafe      // Grid meter failure handling
 
 	// cached state
 	gridPower       float64 // Grid power
@@ -120,14 +129,46 @@ func NewSiteFromConfig(
 		return nil, errors.New("missing either grid or pv meter")
 	}
 
+	// feed-in limitation
+	if site.FeedInLimit != nil {
+		if site.gridMeter == nil || len(site.pvMeters) == 0 {
+			return nil, errors.New("feed-in limit requires grid and pv meter")
+		}
+		site.feedIn = NewFeedInLimit(*site.FeedInLimit)
+	}
+
+	var err error
+	if site.failsafe, err = newFailsafe(site.Failsafe); err != nil {
+		return nil, fmt.Errorf("failsafe: %w", err)
+	}
+
+	// charging requires authorization
+	if len(site.Users) > 0 {
+		users, err := newUsers(site.log, site.Users, len(loadpoints))
+		if err != nil {
+			return nil, fmt.Errorf("users: %w", err)
+		}
+
+		for id, lp := range loadpoints {
+			if _, ok := lp.charger.(api.Identifier); !ok {
+				return nil, fmt.Errorf("users: charger of loadpoint %d must provide identification", id+1)
+			}
+
+			lp.users = users
+			lp.number = id + 1
+		}
+	}
+
 	return site, nil
 }
 
 // NewSite creates a Site with sane defaults
 func NewSite() *Site {
 	lp := &Site{
-		log:     util.NewLogger("site"),
-		Voltage: 230, // V
+		log:           util.NewLogger("site"),
+		Voltage:       230, // V
+		DeviceTimeout: 10 * time.Minute,
+		reconfigureC:  make(chan func()),
 	}
 
 	return lp
@@ -254,12 +295,15 @@ func (site *Site) updateMeters() error {
 			return nil
 		}
 
-		err := retry.Do(site.updateMeter(meter, power), retryOptions...)
+		err := devices.Instance.Measure(meter, func() error {
+			return retry.Do(site.updateMeter(meter, power), deviceRetryOptions(deviceMeter, name)...)
+		})
 
 		if err == nil {
 			site.log.DEBUG.Printf("%s power: %.0fW", name, *power)
 			site.publish(name+"Power", *power)
 		} else {
+			deviceError(deviceMeter, name)
 			err = fmt.Errorf("%s meter: %v", name, err)
 			site.log.ERROR.Println(err)
 		}
@@ -272,7 +316,9 @@ func (site *Site) updateMeters() error {
 
 		for id, meter := range site.pvMeters {
 			var power float64
-			err := retry.Do(site.updateMeter(meter, &power), retryOptions...)
+			err := devices.Instance.Measure(meter, func() error {
+				return retry.Do(site.updateMeter(meter, &power), deviceRetryOptions(deviceMeter, "pv")...)
+			})
 
 			if err == nil {
 				site.pvPower += power
@@ -280,6 +326,7 @@ func (site *Site) updateMeters() error {
 					site.log.WARN.Printf("pv %d power: %.0fW is negative - check configuration if sign is correct", id, power)
 				}
 			} else {
+				deviceError(deviceMeter, "pv")
 				err = fmt.Errorf("pv meter %d: %v", id, err)
 				site.log.ERROR.Println(err)
 			}
@@ -294,11 +341,14 @@ func (site *Site) updateMeters() error {
 
 		for id, meter := range site.batteryMeters {
 			var power float64
-			err := retry.Do(site.updateMeter(meter, &power), retryOptions...)
+			err := devices.Instance.Measure(meter, func() error {
+				return retry.Do(site.updateMeter(meter, &power), deviceRetryOptions(deviceMeter, "battery")...)
+			})
 
 			if err == nil {
 				site.batteryPower += power
 			} else {
+				deviceError(deviceMeter, "battery")
 				site.log.ERROR.Println(fmt.Errorf("battery meter %d: %v", id, err))
 			}
 		}
@@ -381,11 +431,39 @@ func (site *Site) sitePower() (float64, error) {
 	}
 
 	sitePower := sitePower(site.gridPower, batteryPower, site.ResidualPower)
+
+	// probe for pv power curtailed by feed-in limitation
+	if site.feedIn != nil {
+		sitePower -= site.feedInProbe()
+	}
+
 	site.log.DEBUG.Printf("site power: %.0fW", sitePower)
 
 	return sitePower, nil
 }
 
+// feedInProbe returns the additional surplus power assumed while pv is curtailed by feed-in limitation
+func (site *Site) feedInProbe() float64 {
+	var maxPower float64
+	var charging bool
+	for _, lp := range site.loadpoints {
+		maxPower += lp.GetMaxPower()
+		charging = charging || lp.GetStatus() == api.StatusC
+	}
+
+	curtailed := site.feedIn.Curtailed(site.gridPower, site.pvPower, site.batteryPower)
+	probe := site.feedIn.Update(site.gridPower, site.pvPower, site.batteryPower, maxPower, charging)
+
+	if probe > 0 {
+		site.log.DEBUG.Printf("feed-in limit: curtailed, probing %.0fW", probe)
+	}
+
+	site.publish("feedInCurtailed", curtailed)
+	site.publish("feedInProbePower", probe)
+
+	return probe
+}
+
 func (site *Site) update(lp Updater) {
 	site.log.DEBUG.Println("----")
 
@@ -398,12 +476,41 @@ func (site *Site) update(lp Updater) {
 		}
 	}
 
+	// clean grid energy is treated like cheap grid energy
+	if site.tariffs.CO2 != nil && !cheap {
+		if clean, err := site.tariffs.CO2.IsClean(); err == nil && clean {
+			site.log.DEBUG.Println("clean grid energy")
+			cheap = true
+		}
+	}
+
 	var totalChargePower float64
 	for _, lp := range site.loadpoints {
 		totalChargePower += lp.GetChargePower()
 	}
 
-	if sitePower, err := site.sitePower(); err == nil {
+	sitePower, err := site.sitePower()
+
+	wasActive := site.failsafe.active()
+	if site.failsafe.update(err) {
+		site.log.INFO.Println("failsafe: grid meter recovered")
+		site.pushEvent(evFailsafeRecovered, nil)
+	}
+
+	if site.failsafe.active() {
+		if !wasActive {
+			site.pushEvent(evFailsafe, map[string]interface{}{"failsafeMode": site.failsafe.Mode})
+		}
+
+		site.log.WARN.Printf("failsafe: grid meter unavailable, applying %s mode", site.failsafe.Mode)
+		for _, lp := range site.loadpoints {
+			lp.Failsafe(site.failsafe.Mode)
+		}
+	}
+
+	site.publish("failsafe", site.failsafe.active())
+
+	if err == nil {
 		lp.Update(sitePower, cheap, site.batteryBuffered)
 
 		// ignore negative pvPower values as that means it is not an energy source but consumption
@@ -417,6 +524,50 @@ func (site *Site) update(lp Updater) {
 	// update savings
 	// TODO: use energy instead of current power for better results
 	site.savings.Update(site, site.gridPower, site.pvPower, site.batteryPower, totalChargePower)
+
+	// attribute savings to vehicles
+	for _, lp := range site.loadpoints {
+		if vehicle := lp.GetVehicle(); vehicle != nil {
+			site.savings.UpdateVehicle(site, vehicle.Title(), lp.GetChargePower())
+		}
+	}
+
+	site.updateDevices()
+}
+
+// updateDevices publishes device health and reports devices failing for longer than the device timeout
+func (site *Site) updateDevices() {
+	site.publish("devices", devices.Instance.Devices())
+
+	if site.DeviceTimeout <= 0 || site.pushChan == nil {
+		return
+	}
+
+	for _, dev := range devices.Instance.Failed(site.DeviceTimeout) {
+		site.log.WARN.Printf("%s %s failed since %v: %s", dev.Type, dev.Name, dev.FailedSince.Round(time.Second), dev.Error)
+		site.pushEvent(evDeviceFailure, deviceAttributes(dev))
+	}
+
+	for _, dev := range devices.Instance.Recovered() {
+		site.log.INFO.Printf("%s %s recovered", dev.Type, dev.Name)
+		site.pushEvent(evDeviceRecovered, deviceAttributes(dev))
+	}
+}
+
+// deviceAttributes returns the device details for use in message templates
+func deviceAttributes(dev devices.Status) map[string]interface{} {
+	return map[string]interface{}{
+		"deviceType":  dev.Type,
+		"deviceName":  dev.Name,
+		"deviceError": dev.Error,
+	}
+}
+
+// pushEvent sends push messages to clients
+func (site *Site) pushEvent(event string, attr map[string]interface{}) {
+	if site.pushChan != nil {
+		site.pushChan <- push.Event{Event: event, Attributes: attr}
+	}
 }
 
 // prepare publishes initial values
@@ -427,6 +578,7 @@ func (site *Site) prepare() {
 	site.publish("pvConfigured", len(site.pvMeters) > 0)
 	site.publish("batteryConfigured", len(site.batteryMeters) > 0)
 	site.publish("prioritySoC", site.PrioritySoC)
+	site.publish("feedInLimitConfigured", site.feedIn != nil)
 
 	site.publish("currency", site.tariffs.Currency.String())
 	site.publish("savingsSince", site.savings.Since().Unix())
@@ -435,6 +587,7 @@ func (site *Site) prepare() {
 // Prepare attaches communication channels to site and loadpoints
 func (site *Site) Prepare(uiChan chan<- util.Param, pushChan chan<- push.Event) {
 	site.uiChan = uiChan
+	site.pushChan = pushChan
 	site.lpUpdateChan = make(chan *LoadPoint, 1) // 1 capacity to avoid deadlock
 
 	site.prepare()
@@ -490,6 +643,8 @@ func (site *Site) Run(stopC chan struct{}, interval time.Duration) {
 			site.update(<-loadpointChan)
 		case lp := <-site.lpUpdateChan:
 			site.update(lp)
+		case fun := <-site.reconfigureC:
+			fun()
 		case <-stopC:
 			return
 		}
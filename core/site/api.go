@@ -1,10 +1,16 @@
 package site
 
-import "github.com/evcc-io/evcc/core/loadpoint"
+import (
+	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/tariff"
+)
 
 // API is the external site API
 type API interface {
 	Healthy() bool
 	LoadPoints() []loadpoint.API
+	GetTitle() string
+	GetPrioritySoC() float64
 	SetPrioritySoC(float64) error
+	GetTariffs() tariff.Tariffs
 }
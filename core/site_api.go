@@ -4,10 +4,23 @@ import (
 	"errors"
 
 	"github.com/evcc-io/evcc/core/site"
+	"github.com/evcc-io/evcc/tariff"
 )
 
 var _ site.API = (*Site)(nil)
 
+// GetTitle returns the site title
+func (site *Site) GetTitle() string {
+	return site.Title
+}
+
+// GetTariffs returns the site tariffs
+func (site *Site) GetTariffs() tariff.Tariffs {
+	site.Lock()
+	defer site.Unlock()
+	return site.tariffs
+}
+
 // GetPrioritySoC returns the PrioritySoC
 func (site *Site) GetPrioritySoC() float64 {
 	site.Lock()
@@ -0,0 +1,72 @@
+package soc
+
+import (
+	"math"
+	"sort"
+
+	"github.com/evcc-io/evcc/api"
+)
+
+const (
+	minCurveSoC   = 50  // taper is learned above this soc, limits below are usually temporary (cold battery)
+	curveStep     = 5   // soc step of learned curve points in %
+	curveLimiting = 0.9 // vehicle limits charge power if drawing less than this share of offered power
+	curveSettle   = 3   // samples after offered power has changed until charge power is considered steady
+	curveSamples  = 3   // consecutive limited samples required before a limit is learned
+)
+
+// Curve is the vehicle's maximum charge power depending on soc.
+// Power is unlimited below the first point and linearly interpolated between points.
+type Curve []api.ChargeCurvePoint
+
+// NewCurve creates a curve sorted by soc
+func NewCurve(points []api.ChargeCurvePoint) Curve {
+	res := append(Curve(nil), points...)
+	sort.Slice(res, func(i, j int) bool { return res[i].SoC < res[j].SoC })
+	return res
+}
+
+// Power returns the maximum charge power at soc or zero if unlimited
+func (c Curve) Power(soc float64) float64 {
+	if len(c) == 0 || soc < c[0].SoC {
+		return 0
+	}
+
+	for i := 1; i < len(c); i++ {
+		if p0, p1 := c[i-1], c[i]; soc < p1.SoC {
+			return p0.Power + (p1.Power-p0.Power)*(soc-p0.SoC)/(p1.SoC-p0.SoC)
+		}
+	}
+
+	return c[len(c)-1].Power
+}
+
+// learn records the charge power at soc if limited by the vehicle and returns true if the curve has changed.
+// Learned limits are raised once the vehicle draws more power.
+func (c *Curve) learn(soc, chargePower, offeredPower float64) bool {
+	if soc < minCurveSoC || chargePower <= 0 || offeredPower <= 0 {
+		return false
+	}
+
+	bucket := math.Floor(soc/curveStep) * curveStep
+	limited := chargePower < curveLimiting*offeredPower
+
+	for i, p := range *c {
+		if p.SoC == bucket {
+			if limited || chargePower > p.Power {
+				changed := math.Abs(p.Power-chargePower) >= 100
+				(*c)[i].Power = chargePower
+				return changed
+			}
+			return false
+		}
+	}
+
+	if !limited {
+		return false
+	}
+
+	*c = NewCurve(append(*c, api.ChargeCurvePoint{SoC: bucket, Power: chargePower}))
+
+	return true
+}
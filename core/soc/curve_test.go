@@ -0,0 +1,143 @@
+package soc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/mock"
+	"github.com/evcc-io/evcc/util"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCurvePower(t *testing.T) {
+	c := NewCurve([]api.ChargeCurvePoint{
+		{SoC: 100, Power: 2000},
+		{SoC: 80, Power: 11000},
+		{SoC: 90, Power: 5000},
+	})
+
+	for _, tc := range []struct {
+		soc, power float64
+	}{
+		{50, 0}, // unlimited
+		{80, 11000},
+		{85, 8000},
+		{95, 3500},
+		{100, 2000},
+	} {
+		if power := c.Power(tc.soc); power != tc.power {
+			t.Errorf("%.0f%%: expected %.0fW, got %.0fW", tc.soc, tc.power, power)
+		}
+	}
+}
+
+func TestCurveLearn(t *testing.T) {
+	var c Curve
+
+	// below minimum soc
+	if c.learn(40, 5000, 11000) {
+		t.Error("unexpected learning below minimum soc")
+	}
+
+	// not limited by vehicle
+	if c.learn(82, 10500, 11000) || len(c) > 0 {
+		t.Error("unexpected learning of unlimited power")
+	}
+
+	// limited by vehicle
+	if !c.learn(92, 5000, 11000) || !c.learn(87, 8000, 11000) {
+		t.Error("expected learning of limited power")
+	}
+
+	if len(c) != 2 || c[0] != (api.ChargeCurvePoint{SoC: 85, Power: 8000}) || c[1] != (api.ChargeCurvePoint{SoC: 90, Power: 5000}) {
+		t.Errorf("unexpected curve: %+v", c)
+	}
+
+	// vehicle draws more than learned
+	if !c.learn(86, 10500, 11000) || c[0].Power != 10500 {
+		t.Errorf("expected learned limit raised: %+v", c)
+	}
+
+	// lower offered power does not lower limit
+	if c.learn(91, 3600, 3700) || c[1].Power != 5000 {
+		t.Errorf("unexpected learned limit: %+v", c)
+	}
+}
+
+func TestEstimatorSampleCurve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Capacity().Return(int64(50)).AnyTimes()
+
+	ce := NewEstimator(util.NewLogger("foo"), nil, vehicle, false)
+	ce.vehicleSoc = 90
+
+	sample := func(n int, chargePower, offeredPower float64) (changed bool) {
+		for i := 0; i < n; i++ {
+			changed = ce.SampleCurve(chargePower, offeredPower, 1400) || changed
+		}
+		return changed
+	}
+
+	// ramp-up after enable
+	if sample(curveSettle, 2000, 11000) || len(ce.learnedCurve) > 0 {
+		t.Error("unexpected learning during ramp-up")
+	}
+
+	// single limited sample
+	if sample(curveSamples-1, 5000, 11000) {
+		t.Error("unexpected learning from single sample")
+	}
+
+	// trickle charging
+	if sample(curveSamples, 1000, 11000) || len(ce.learnedCurve) > 0 {
+		t.Error("unexpected learning of trickle charging")
+	}
+
+	// steady limited
+	if !sample(curveSamples, 5000, 11000) || ce.learnedCurve.Power(90) != 5000 {
+		t.Errorf("expected learning of steady limit: %+v", ce.learnedCurve)
+	}
+
+	// current change restarts settling
+	if sample(curveSettle+curveSamples-1, 3000, 7400) || ce.learnedCurve.Power(90) != 5000 {
+		t.Errorf("unexpected learning after current change: %+v", ce.learnedCurve)
+	}
+}
+
+func TestEstimatorChargeCurve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	charger := mock.NewMockCharger(ctrl)
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Capacity().Return(int64(50)).AnyTimes()
+
+	ce := NewEstimator(util.NewLogger("foo"), charger, vehicle, false)
+	ce.vehicleSoc = 70
+
+	// 556Wh/% at default efficiency: 10% unlimited, 10% tapering from 11kW to 2kW, 10% at 2kW
+	linear := ce.AssumedChargeDuration(100, 11000)
+
+	ce.LearnCurve([]api.ChargeCurvePoint{{SoC: 80, Power: 11000}, {SoC: 90, Power: 1000}, {SoC: 100, Power: 1000}})
+	ce.SetCurve([]api.ChargeCurvePoint{{SoC: 80, Power: 11000}, {SoC: 90, Power: 2000}, {SoC: 100, Power: 2000}})
+
+	if !ce.Learned() {
+		t.Error("expected charge curve to be considered learned")
+	}
+
+	tapered := ce.AssumedChargeDuration(100, 11000)
+	if tapered <= linear {
+		t.Errorf("expected taper to increase duration: %v <= %v", tapered, linear)
+	}
+
+	// configured curve takes precedence
+	within(t, "tapered duration", (4*time.Hour + 20*time.Minute).Hours(), tapered.Hours(), 0.02)
+
+	// unlimited below curve
+	if d := ce.AssumedChargeDuration(80, 11000); d != (30*time.Minute + 18*time.Second) {
+		t.Errorf("unexpected duration below curve: %v", d)
+	}
+
+	// energy is not affected without power dependent losses
+	within(t, "energy", 16.7, ce.RemainingChargeEnergy(11000, 100), 0.01)
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/evcc-io/evcc/util"
 )
 
-const chargeEfficiency = 0.9 // assume charge 90% efficiency
+const chargeEfficiency = 0.9 // assume charge 90% efficiency until sessions have been learned
 
 // Estimator provides vehicle soc and charge duration
 // Vehicle SoC can be estimated to provide more granularity
@@ -27,6 +27,18 @@ type Estimator struct {
 	prevSoc           float64 // previous vehicle SoC in %
 	prevChargedEnergy float64 // previous charged energy in Wh
 	energyPerSocStep  float64 // Energy per SoC percent in Wh
+
+	model          *model  // learned energy per soc percent of previous sessions
+	session        Session // current session's soc gradient
+	lastEnergy     float64 // charged energy at previous call in Wh
+	weightedPower  float64 // sum of charge power weighted by energy in Wh*W
+	weightedEnergy float64 // sum of weighting energy in Wh
+
+	curve        Curve   // configured charge curve
+	learnedCurve Curve   // charge curve learned from vehicle limited charge power
+	curveOffered float64 // offered power of previous curve sample in W
+	curveSteady  int     // curve samples since offered power has changed
+	curveLimited int     // consecutive limited curve samples
 }
 
 // NewEstimator creates new estimator
@@ -36,6 +48,7 @@ func NewEstimator(log *util.Logger, charger api.Charger, vehicle api.Vehicle, es
 		charger:  charger,
 		vehicle:  vehicle,
 		estimate: estimate,
+		model:    new(model),
 	}
 
 	s.Reset()
@@ -51,9 +64,157 @@ func (s *Estimator) Reset() {
 	s.capacity = float64(s.vehicle.Capacity()) * 1e3  // cache to simplify debugging
 	s.virtualCapacity = s.capacity / chargeEfficiency // initial capacity taking efficiency into account
 	s.energyPerSocStep = s.virtualCapacity / 100
+
+	// start with learned energy per soc percent
+	if e0, ok := s.model.energy(0); ok {
+		s.energyPerSocStep = e0
+		s.virtualCapacity = 100 * e0
+	}
+
+	s.session = Session{}
+	s.lastEnergy = 0
+	s.weightedPower = 0
+	s.weightedEnergy = 0
+
+	s.curveOffered = 0
+	s.curveSteady = 0
+	s.curveLimited = 0
+}
+
+// Learn adds previously recorded charging sessions to the efficiency model
+func (s *Estimator) Learn(sessions ...Session) {
+	s.model.add(sessions...)
+	s.log.DEBUG.Printf("soc model: %d sessions, %.1fWh/%%, %.0fW loss", len(s.model.sessions), s.model.e0, s.model.loss)
+
+	if s.session.SoC == 0 {
+		s.Reset()
+	}
+}
+
+// Sessions returns the recorded charging sessions
+func (s *Estimator) Sessions() []Session {
+	return append([]Session(nil), s.model.sessions...)
+}
+
+// SetCurve sets the vehicle's configured charge curve which takes precedence over the learned curve
+func (s *Estimator) SetCurve(curve []api.ChargeCurvePoint) {
+	s.curve = NewCurve(curve)
+}
+
+// LearnCurve adds previously learned charge curve points
+func (s *Estimator) LearnCurve(curve []api.ChargeCurvePoint) {
+	s.learnedCurve = NewCurve(curve)
+}
+
+// LearnedCurve returns the learned charge curve
+func (s *Estimator) LearnedCurve() Curve {
+	return append(Curve(nil), s.learnedCurve...)
+}
+
+// SampleCurve records the charge power at current soc if limited by the vehicle.
+// It must be called once per cycle, offered power is zero while not charging.
+// Limits are only learned after charge power has settled since offered power has last changed,
+// from several consecutive limited samples and above minimum power to exclude ramp-up and trickle charging.
+// It returns true if the learned curve has changed.
+func (s *Estimator) SampleCurve(chargePower, offeredPower, minPower float64) bool {
+	if offeredPower != s.curveOffered {
+		s.curveOffered = offeredPower
+		s.curveSteady = 0
+		s.curveLimited = 0
+	}
+
+	if s.curveSteady < curveSettle {
+		s.curveSteady++
+		return false
+	}
+
+	if offeredPower <= 0 || chargePower < minPower {
+		s.curveLimited = 0
+		return false
+	}
+
+	if chargePower < curveLimiting*offeredPower {
+		if s.curveLimited++; s.curveLimited < curveSamples {
+			return false
+		}
+	} else {
+		s.curveLimited = 0
+	}
+
+	changed := s.learnedCurve.learn(s.vehicleSoc, chargePower, offeredPower)
+	if changed {
+		s.log.DEBUG.Printf("charge curve updated: %.0fW at %.0f%%", chargePower, s.vehicleSoc)
+	}
+
+	return changed
+}
+
+// Learned returns true if estimates are based on previous sessions or a charge curve
+// and already account for charging losses
+func (s *Estimator) Learned() bool {
+	_, ok := s.model.energy(0)
+	return ok || len(s.chargeCurve()) > 0
+}
+
+// chargeCurve returns the configured or learned charge curve
+func (s *Estimator) chargeCurve() Curve {
+	if len(s.curve) > 0 {
+		return s.curve
+	}
+	return s.learnedCurve
+}
+
+// integrate returns remaining energy in Wh and duration in h up to targetSoC at charge power limited by the charge curve
+func (s *Estimator) integrate(curve Curve, targetSoC int, chargePower float64) (energy, hours float64) {
+	for soc := s.vehicleSoc; soc < float64(targetSoC); {
+		step := math.Min(1, float64(targetSoC)-soc)
+
+		power := chargePower
+		if limit := curve.Power(soc + step/2); limit > 0 {
+			power = math.Min(power, limit)
+		}
+
+		e := step * s.energyPerSoC(power)
+		energy += e
+		hours += e / power
+
+		soc += step
+	}
+
+	return energy, hours
+}
+
+// Finish completes the charging session and learns from it.
+// It returns true if the session has been suitable for learning.
+func (s *Estimator) Finish() bool {
+	session := s.session
+	s.Reset()
+
+	if session.SoC < minSessionSoC || session.Power <= 0 {
+		return false
+	}
+
+	s.Learn(session)
+
+	return true
 }
 
-// AssumedChargeDuration estimates charge duration up to targetSoC based on virtual capacity
+// energyPerSoC returns the charged energy per soc percent at charge power
+func (s *Estimator) energyPerSoC(chargePower float64) float64 {
+	// current session's gradient, scaled from session's average power
+	if s.session.SoC > 0 {
+		return s.energyPerSocStep * s.model.scale(chargePower) / s.model.scale(s.session.Power)
+	}
+
+	if e, ok := s.model.energy(chargePower); ok {
+		return e
+	}
+
+	return s.energyPerSocStep
+}
+
+// AssumedChargeDuration estimates charge duration up to targetSoC based on learned energy per soc percent at charge power.
+// Charge power is limited by the vehicle's charge curve if available.
 func (s *Estimator) AssumedChargeDuration(targetSoC int, chargePower float64) time.Duration {
 	percentRemaining := float64(targetSoC) - s.vehicleSoc
 
@@ -61,7 +222,12 @@ func (s *Estimator) AssumedChargeDuration(targetSoC int, chargePower float64) ti
 		return 0
 	}
 
-	whRemaining := percentRemaining / 100 * s.virtualCapacity
+	if curve := s.chargeCurve(); len(curve) > 0 && chargePower > 0 {
+		_, hours := s.integrate(curve, targetSoC, chargePower)
+		return time.Duration(float64(time.Hour) * hours).Round(time.Second)
+	}
+
+	whRemaining := percentRemaining * s.energyPerSoC(chargePower)
 	return time.Duration(float64(time.Hour) * whRemaining / chargePower).Round(time.Second)
 }
 
@@ -92,22 +258,39 @@ func (s *Estimator) RemainingChargeDuration(chargePower float64, targetSoC int)
 	return -1
 }
 
-// RemainingChargeEnergy returns the remaining charge energy in kWh
-func (s *Estimator) RemainingChargeEnergy(targetSoC int) float64 {
+// RemainingChargeEnergy returns the remaining charge energy in kWh at charge power
+func (s *Estimator) RemainingChargeEnergy(chargePower float64, targetSoC int) float64 {
 	percentRemaining := float64(targetSoC) - s.vehicleSoc
 	if percentRemaining <= 0 || s.virtualCapacity <= 0 {
 		return 0
 	}
 
+	if curve := s.chargeCurve(); len(curve) > 0 && chargePower > 0 {
+		whRemaining, _ := s.integrate(curve, targetSoC, chargePower)
+		return whRemaining / 1e3
+	}
+
 	// estimate remaining energy
-	whRemaining := percentRemaining / 100 * s.virtualCapacity
+	whRemaining := percentRemaining * s.energyPerSoC(chargePower)
 	return whRemaining / 1e3
 }
 
-// SoC replaces the api.Vehicle.SoC interface to take charged energy into account
-func (s *Estimator) SoC(chargedEnergy float64) (float64, error) {
+// samplePower tracks the session's average charge power weighted by charged energy
+func (s *Estimator) samplePower(chargedEnergy, chargePower float64) {
+	if delta := chargedEnergy - s.lastEnergy; delta > 0 && chargePower > 0 {
+		s.weightedPower += delta * chargePower
+		s.weightedEnergy += delta
+	}
+
+	s.lastEnergy = chargedEnergy
+}
+
+// SoC replaces the api.Vehicle.SoC interface to take charged energy and charge power into account
+func (s *Estimator) SoC(chargedEnergy, chargePower float64) (float64, error) {
 	var fetchedSoC *float64
 
+	s.samplePower(chargedEnergy, chargePower)
+
 	if charger, ok := s.charger.(api.Battery); ok {
 		f, err := charger.SoC()
 
@@ -183,9 +366,14 @@ func (s *Estimator) SoC(chargedEnergy float64) (float64, error) {
 				energyDiff := chargedEnergy - s.initialEnergy
 
 				// recalculate gradient, wh per soc %
-				if socDiff > 10 && energyDiff > 0 {
+				if socDiff > minSessionSoC && energyDiff > 0 {
 					s.energyPerSocStep = energyDiff / socDiff
 					s.virtualCapacity = s.energyPerSocStep * 100
+
+					s.session = Session{SoC: socDiff, Energy: energyDiff}
+					if s.weightedEnergy > 0 {
+						s.session.Power = s.weightedPower / s.weightedEnergy
+					}
 					s.log.DEBUG.Printf("soc gradient updated: soc: %.1f%%, socDiff: %.1f%%, energyDiff: %.0fWh, energyPerSocStep: %.1fWh, virtualCapacity: %.0fWh", s.vehicleSoc, socDiff, energyDiff, s.energyPerSocStep, s.virtualCapacity)
 				}
 			}
@@ -194,7 +382,7 @@ func (s *Estimator) SoC(chargedEnergy float64) (float64, error) {
 			s.prevChargedEnergy = math.Max(chargedEnergy, 0)
 			s.prevSoc = s.vehicleSoc
 		} else {
-			s.vehicleSoc = math.Min(*fetchedSoC+energyDelta/s.energyPerSocStep, 100)
+			s.vehicleSoc = math.Min(*fetchedSoC+energyDelta/s.energyPerSoC(chargePower), 100)
 			s.log.DEBUG.Printf("soc estimated: %.2f%% (vehicle: %.2f%%)", s.vehicleSoc, *fetchedSoC)
 		}
 	}
@@ -85,7 +85,7 @@ func TestSoCEstimation(t *testing.T) {
 				vehicle.EXPECT().SoC().Return(tc.vehicleSoC, nil)
 			}
 
-			soc, err := ce.SoC(tc.chargedEnergy)
+			soc, err := ce.SoC(tc.chargedEnergy, 1e3)
 			if err != nil {
 				t.Error(err)
 			}
@@ -177,7 +177,7 @@ func TestSoCFromChargerAndVehicleWithErrors(t *testing.T) {
 			vehicle.EXPECT().SoC().Return(tc.vehicleSoC, tc.vehicleError)
 		}
 
-		soc, err := ce.SoC(tc.chargedEnergy)
+		soc, err := ce.SoC(tc.chargedEnergy, 1e3)
 		if err != nil {
 			if (!tc.expectVehicle && err != tc.chargerError) || (tc.expectVehicle && err != tc.vehicleError) {
 				t.Error(err)
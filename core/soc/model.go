@@ -0,0 +1,93 @@
+package soc
+
+const (
+	maxSessions   = 20   // charging sessions kept for learning
+	minSessionSoC = 10   // minimum soc increase in % of a session used for learning
+	maxLoss       = 1000 // maximum constant charging loss in W
+)
+
+// Session is the soc increase, charged energy and average charge power of a charging session
+type Session struct {
+	SoC    float64 `json:"soc"`    // soc increase in %
+	Energy float64 `json:"energy"` // charged energy in Wh
+	Power  float64 `json:"power"`  // average charge power in W
+}
+
+// model describes the charged energy per soc percent as function of charge power
+//
+//	e(P) = e0 * P / (P - loss)
+//
+// where e0 is the energy per percent without constant losses and loss is the power consumed
+// by on-board charger and battery conditioning while charging. Slow charging is therefore less efficient.
+type model struct {
+	sessions []Session
+	e0, loss float64 // zero if unknown
+}
+
+// add adds charging sessions and refits the model
+func (m *model) add(sessions ...Session) {
+	for _, s := range sessions {
+		if s.SoC >= minSessionSoC && s.Energy > 0 && s.Power > 0 {
+			m.sessions = append(m.sessions, s)
+		}
+	}
+
+	if len(m.sessions) > maxSessions {
+		m.sessions = m.sessions[len(m.sessions)-maxSessions:]
+	}
+
+	m.fit()
+}
+
+// fit determines e0 and loss by weighted least squares of soc percent per Wh y = 1/e0 - loss/e0 * 1/P
+func (m *model) fit() {
+	m.e0, m.loss = 0, 0
+
+	var sw, sx, sy, sxx, sxy float64
+	for _, s := range m.sessions {
+		w, x, y := s.SoC, 1/s.Power, s.SoC/s.Energy
+		sw += w
+		sx += w * x
+		sy += w * y
+		sxx += w * x * x
+		sxy += w * x * y
+	}
+
+	if sw == 0 {
+		return
+	}
+
+	mx, my := sx/sw, sy/sw
+	m.e0 = 1 / my
+
+	// losses can only be determined from sessions with different charge power (relative deviation > 10%)
+	if varx := sxx/sw - mx*mx; varx > 0.01*mx*mx {
+		b := (mx*my - sxy/sw) / varx
+		if a := my + b*mx; a > 0 && b > 0 && b/a <= maxLoss {
+			m.e0, m.loss = 1/a, b/a
+		}
+	}
+}
+
+// scale returns the factor of energy per soc percent at charge power due to constant losses.
+// Unknown power is treated as loss-free.
+func (m *model) scale(power float64) float64 {
+	if m.loss == 0 || power <= 0 {
+		return 1
+	}
+
+	// limit to 50% efficiency loss at very low power
+	if power < 2*m.loss {
+		power = 2 * m.loss
+	}
+
+	return power / (power - m.loss)
+}
+
+// energy returns the energy per soc percent at charge power
+func (m *model) energy(power float64) (float64, bool) {
+	if m.e0 == 0 {
+		return 0, false
+	}
+	return m.e0 * m.scale(power), true
+}
@@ -0,0 +1,218 @@
+package soc
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/mock"
+	"github.com/evcc-io/evcc/util"
+	"github.com/golang/mock/gomock"
+)
+
+// sample is a charged energy in Wh and vehicle soc in %
+type sample struct {
+	energy float64
+	soc    float64
+}
+
+// synthetic traces computed for a 50kWh vehicle with 94% charge efficiency and 250W constant charging loss,
+// soc is rounded to full percent like reported by vehicle apis
+var (
+	// 1p slow charging, ~597Wh/%
+	synthetic1p = []sample{
+		{0, 20}, {1200, 22}, {2400, 24}, {3600, 26}, {4800, 28}, {6000, 30}, {7200, 32}, {8400, 34}, {9600, 36}, {10800, 38}, {12000, 40},
+		{13200, 42}, {14400, 44}, {15600, 46}, {16800, 48}, {18000, 50}, {19200, 52}, {20400, 54}, {21600, 56}, {22800, 58}, {24000, 60},
+	}
+	// 3p fast charging, ~544Wh/%
+	synthetic3p = []sample{
+		{0, 31}, {2500, 36}, {5000, 40}, {7500, 45}, {10000, 49}, {12500, 54}, {15000, 59}, {17500, 63}, {20000, 68}, {22500, 72}, {25000, 77},
+	}
+)
+
+func within(t *testing.T, name string, expected, actual, tolerance float64) {
+	t.Helper()
+	if math.Abs(actual-expected) > tolerance*expected {
+		t.Errorf("%s: expected %.1f ±%.0f%%, got %.1f", name, expected, 100*tolerance, actual)
+	}
+}
+
+func TestEstimatorLearning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	charger := mock.NewMockCharger(ctrl)
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Capacity().Return(int64(50)).AnyTimes()
+
+	ce := NewEstimator(util.NewLogger("foo"), charger, vehicle, true)
+	if ce.Learned() {
+		t.Error("unexpected learned estimator without sessions")
+	}
+
+	for _, tc := range []struct {
+		trace []sample
+		power float64
+	}{
+		{synthetic1p, 2300},
+		{synthetic3p, 11000},
+	} {
+		for _, s := range tc.trace {
+			vehicle.EXPECT().SoC().Return(s.soc, nil)
+			if _, err := ce.SoC(s.energy, tc.power); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if !ce.Finish() {
+			t.Fatal("session not learned")
+		}
+	}
+
+	if sessions := ce.Sessions(); len(sessions) != 2 || sessions[0].Power != 2300 || sessions[1].SoC != 46 {
+		t.Errorf("unexpected sessions: %+v", sessions)
+	}
+
+	within(t, "e0", 531.9, ce.model.e0, 0.02)
+	within(t, "loss", 250, ce.model.loss, 0.15)
+
+	// restored from recorded sessions
+	restored := NewEstimator(util.NewLogger("foo"), charger, vehicle, true)
+	restored.Learn(ce.Sessions()...)
+	restored.vehicleSoc = 50
+
+	if !restored.Learned() {
+		t.Error("expected learned estimator")
+	}
+
+	within(t, "1p duration", (7*time.Hour + 47*time.Minute).Hours(), restored.AssumedChargeDuration(80, 2300).Hours(), 0.02)
+	within(t, "3p duration", (time.Hour + 29*time.Minute).Hours(), restored.AssumedChargeDuration(80, 11000).Hours(), 0.02)
+
+	within(t, "1p energy", 17.9, restored.RemainingChargeEnergy(2300, 80), 0.02)
+	within(t, "3p energy", 16.3, restored.RemainingChargeEnergy(11000, 80), 0.02)
+}
+
+func TestEstimatorLearningSkipsShortSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	charger := mock.NewMockCharger(ctrl)
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Capacity().Return(int64(50)).AnyTimes()
+
+	ce := NewEstimator(util.NewLogger("foo"), charger, vehicle, true)
+
+	for _, s := range synthetic3p[:3] {
+		vehicle.EXPECT().SoC().Return(s.soc, nil)
+		if _, err := ce.SoC(s.energy, 11000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ce.Finish() || len(ce.Sessions()) > 0 {
+		t.Error("unexpected session learned")
+	}
+
+	// default efficiency
+	if ce.virtualCapacity != 50e3/chargeEfficiency {
+		t.Errorf("unexpected virtual capacity: %.0f", ce.virtualCapacity)
+	}
+}
+
+func TestModelFit(t *testing.T) {
+	// same power, losses cannot be determined
+	m := new(model)
+	m.add(Session{SoC: 40, Energy: 24000, Power: 11000}, Session{SoC: 20, Energy: 10800, Power: 11000})
+
+	if m.loss != 0 {
+		t.Errorf("unexpected loss: %.0fW", m.loss)
+	}
+	within(t, "e0", 578.6, m.e0, 0.001)
+
+	// sessions below minimum soc increase are ignored
+	m.add(Session{SoC: 5, Energy: 10000, Power: 2000})
+	if len(m.sessions) != 2 {
+		t.Errorf("unexpected sessions: %+v", m.sessions)
+	}
+
+	// limited number of sessions
+	for i := 0; i < maxSessions; i++ {
+		m.add(Session{SoC: 20, Energy: 10000, Power: 7000})
+	}
+	if len(m.sessions) != maxSessions {
+		t.Errorf("expected %d sessions, got %d", maxSessions, len(m.sessions))
+	}
+	within(t, "e0", 500, m.e0, 0.001)
+}
+
+// recording is a charging session recorded from an actual vehicle, stored as testdata/*.json
+type recording struct {
+	Source    string       `json:"source"`    // vehicle and origin of the log
+	Capacity  int64        `json:"capacity"`  // vehicle capacity in kWh
+	Power     float64      `json:"power"`     // charge power in W
+	Tolerance float64      `json:"tolerance"` // relative tolerance of the learned energy per percent
+	Samples   [][2]float64 `json:"samples"`   // charged energy in Wh and vehicle soc in %
+}
+
+// replay learns the recorded session and returns the learned and the measured energy per percent
+func (r recording) replay(t *testing.T) (float64, float64) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	charger := mock.NewMockCharger(ctrl)
+	vehicle := mock.NewMockVehicle(ctrl)
+	vehicle.EXPECT().Capacity().Return(r.Capacity).AnyTimes()
+
+	ce := NewEstimator(util.NewLogger("foo"), charger, vehicle, true)
+
+	for _, s := range r.Samples {
+		vehicle.EXPECT().SoC().Return(s[1], nil)
+		if _, err := ce.SoC(s[0], r.Power); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !ce.Finish() {
+		t.Fatalf("%s: session not learned", r.Source)
+	}
+
+	first, last := r.Samples[0], r.Samples[len(r.Samples)-1]
+	learned, _ := ce.model.energy(r.Power)
+
+	return learned, (last[0] - first[0]) / (last[1] - first[1])
+}
+
+func TestEstimatorRecordedSessions(t *testing.T) {
+	recordings := []recording{
+		{Source: "synthetic 1p", Capacity: 50, Power: 2300, Tolerance: 0.02},
+		{Source: "synthetic 3p", Capacity: 50, Power: 11000, Tolerance: 0.02},
+	}
+	for i, trace := range [][]sample{synthetic1p, synthetic3p} {
+		for _, s := range trace {
+			recordings[i].Samples = append(recordings[i].Samples, [2]float64{s.energy, s.soc})
+		}
+	}
+
+	files, err := filepath.Glob("testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var r recording
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		recordings = append(recordings, r)
+	}
+
+	for _, r := range recordings {
+		learned, measured := r.replay(t)
+		within(t, r.Source, measured, learned, r.Tolerance)
+	}
+}
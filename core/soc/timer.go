@@ -107,7 +107,11 @@ func (lp *Timer) DemandActive() bool {
 	}
 
 	// time
-	remainingDuration := time.Duration(float64(se.AssumedChargeDuration(lp.SoC, power)) / chargeEfficiency)
+	remainingDuration := se.AssumedChargeDuration(lp.SoC, power)
+	if !se.Learned() {
+		// add safety margin while estimate relies on default efficiency
+		remainingDuration = time.Duration(float64(remainingDuration) / chargeEfficiency)
+	}
 	lp.finishAt = time.Now().Add(remainingDuration).Round(time.Minute)
 
 	lp.log.DEBUG.Printf("estimated charge duration: %v to %d%% at %.0fW", remainingDuration.Round(time.Minute), lp.SoC, power)
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/store"
+)
+
+// Quota periods
+const (
+	periodDay   = "day"
+	periodWeek  = "week"
+	periodMonth = "month"
+)
+
+// UserConfig defines a user allowed to charge after identifying with one of its tags
+type UserConfig struct {
+	Name       string   `mapstructure:"name"`
+	Tags       []string `mapstructure:"tags"`       // rfid tags or charger ids
+	LoadPoints []int    `mapstructure:"loadpoints"` // allowed loadpoint numbers starting at 1, default all
+	Times      []string `mapstructure:"times"`      // allowed times of day as hh:mm-hh:mm, default always
+	Quota      float64  `mapstructure:"quota"`      // energy quota per period in kWh, default unlimited
+	Period     string   `mapstructure:"period"`     // quota period day, week or month (default)
+}
+
+// user is a registered user and its energy consumption
+type user struct {
+	UserConfig
+	times [][2]int // allowed times as minutes of day
+	userConsumption
+}
+
+// userConsumption is the persisted energy consumption of a user
+type userConsumption struct {
+	Consumed float64   `json:"consumed"` // energy charged within current period in Wh
+	Start    time.Time `json:"start"`    // start of current period
+}
+
+// userSession is a user's running charging session
+type userSession struct {
+	user   *user
+	energy float64 // energy charged in current session in Wh
+}
+
+// users is the registry of users allowed to charge
+type users struct {
+	mu       sync.Mutex
+	log      *util.Logger
+	users    []*user
+	sessions map[int]userSession // running sessions by loadpoint
+}
+
+// timeRange parses hh:mm-hh:mm into minutes of day
+func timeRange(s string) ([2]int, error) {
+	var res [2]int
+
+	segs := strings.Split(s, "-")
+	if len(segs) != 2 {
+		return res, fmt.Errorf("invalid time range: %s", s)
+	}
+
+	for i, seg := range segs {
+		ts, err := time.Parse("15:04", strings.TrimSpace(seg))
+		if err != nil {
+			return res, fmt.Errorf("invalid time range: %s", s)
+		}
+		res[i] = 60*ts.Hour() + ts.Minute()
+	}
+
+	return res, nil
+}
+
+// userKey is the store key of the user's energy consumption
+func userKey(name string) string {
+	return "user." + name + ".consumption"
+}
+
+// newUsers creates the user registry from config and restores the users' energy consumption
+func newUsers(log *util.Logger, cc []UserConfig, loadpoints int) (*users, error) {
+	res := &users{
+		log:      log,
+		sessions: make(map[int]userSession),
+	}
+	tags := make(map[string]string)
+
+	for _, uc := range cc {
+		if uc.Name == "" {
+			return nil, errors.New("missing name")
+		}
+
+		if len(uc.Tags) == 0 {
+			return nil, fmt.Errorf("%s: missing tags", uc.Name)
+		}
+
+		for _, tag := range uc.Tags {
+			if other, ok := tags[strings.ToLower(tag)]; ok {
+				return nil, fmt.Errorf("%s: tag %s already assigned to %s", uc.Name, tag, other)
+			}
+			tags[strings.ToLower(tag)] = uc.Name
+		}
+
+		for _, id := range uc.LoadPoints {
+			if id < 1 || id > loadpoints {
+				return nil, fmt.Errorf("%s: invalid loadpoint: %d", uc.Name, id)
+			}
+		}
+
+		switch uc.Period = strings.ToLower(uc.Period); uc.Period {
+		case "":
+			uc.Period = periodMonth
+		case periodDay, periodWeek, periodMonth:
+		default:
+			return nil, fmt.Errorf("%s: invalid period: %s", uc.Name, uc.Period)
+		}
+
+		u := &user{UserConfig: uc}
+
+		for _, s := range uc.Times {
+			tr, err := timeRange(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", uc.Name, err)
+			}
+			u.times = append(u.times, tr)
+		}
+
+		if _, err := store.Instance.Get(userKey(u.Name), &u.userConsumption); err != nil {
+			log.ERROR.Printf("user %s: %v", u.Name, err)
+		}
+
+		res.users = append(res.users, u)
+	}
+
+	return res, nil
+}
+
+// identify returns the user owning the tag
+func (r *users) identify(tag string) *user {
+	for _, u := range r.users {
+		for _, t := range u.Tags {
+			if strings.EqualFold(t, tag) {
+				return u
+			}
+		}
+	}
+
+	return nil
+}
+
+// periodStart returns the start of the quota period containing ts
+func (u *user) periodStart(ts time.Time) time.Time {
+	day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, ts.Location())
+
+	switch u.Period {
+	case periodDay:
+		return day
+	case periodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+}
+
+// remaining returns the remaining energy quota at ts in Wh, excluding running sessions
+func (r *users) remaining(u *user, ts time.Time) float64 {
+	if u.Quota <= 0 {
+		return math.Inf(1)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if start := u.periodStart(ts); !start.Equal(u.Start) {
+		u.Start = start
+		u.Consumed = 0
+	}
+
+	return 1e3*u.Quota - u.Consumed
+}
+
+// running updates the energy of the user's session at the loadpoint
+// and returns the energy charged by all running sessions of the user in Wh
+func (r *users) running(u *user, loadpoint int, energy float64) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[loadpoint] = userSession{user: u, energy: energy}
+
+	var res float64
+	for _, s := range r.sessions {
+		if s.user == u {
+			res += s.energy
+		}
+	}
+
+	return res
+}
+
+// consume completes the user's session at the loadpoint and adds the charged energy in Wh to the user's quota period
+func (r *users) consume(u *user, loadpoint int, energy float64, ts time.Time) {
+	_ = r.remaining(u, ts) // roll over period
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, loadpoint)
+	u.Consumed += energy
+
+	if err := store.Instance.Set(userKey(u.Name), u.userConsumption); err != nil {
+		r.log.ERROR.Printf("user %s: %v", u.Name, err)
+	}
+}
+
+// permit returns an error if the user must not charge at the loadpoint at ts,
+// given the energy already charged in the current session and the user's sessions at other loadpoints
+func (r *users) permit(u *user, loadpoint int, energy float64, ts time.Time) error {
+	if len(u.LoadPoints) > 0 {
+		var ok bool
+		for _, id := range u.LoadPoints {
+			ok = ok || id == loadpoint
+		}
+
+		if !ok {
+			return fmt.Errorf("%s: loadpoint %d not allowed", u.Name, loadpoint)
+		}
+	}
+
+	if len(u.times) > 0 {
+		m := 60*ts.Hour() + ts.Minute()
+
+		var ok bool
+		for _, tr := range u.times {
+			if tr[0] <= tr[1] {
+				ok = ok || m >= tr[0] && m < tr[1]
+			} else {
+				ok = ok || m >= tr[0] || m < tr[1]
+			}
+		}
+
+		if !ok {
+			return fmt.Errorf("%s: outside allowed times", u.Name)
+		}
+	}
+
+	if r.remaining(u, ts) <= r.running(u, loadpoint, energy) {
+		return fmt.Errorf("%s: energy quota exhausted", u.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/mock"
+	"github.com/evcc-io/evcc/push"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/store"
+	"github.com/golang/mock/gomock"
+)
+
+func TestUsersConfig(t *testing.T) {
+	for _, cc := range [][]UserConfig{
+		{{Tags: []string{"a"}}},
+		{{Name: "foo"}},
+		{{Name: "foo", Tags: []string{"a"}}, {Name: "bar", Tags: []string{"A"}}},
+		{{Name: "foo", Tags: []string{"a"}, LoadPoints: []int{3}}},
+		{{Name: "foo", Tags: []string{"a"}, Times: []string{"07:00"}}},
+		{{Name: "foo", Tags: []string{"a"}, Period: "year"}},
+	} {
+		if _, err := newUsers(util.NewLogger("foo"), cc, 2); err == nil {
+			t.Errorf("%+v: expected error", cc)
+		}
+	}
+}
+
+func TestUsersPermit(t *testing.T) {
+	r, err := newUsers(util.NewLogger("foo"), []UserConfig{
+		{Name: "foo", Tags: []string{"04AB"}, LoadPoints: []int{2}, Times: []string{"22:00-06:00", "12:00-13:00"}},
+		{Name: "bar", Tags: []string{"05CD"}, Quota: 10, Period: "week"},
+	}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u := r.identify("unknown"); u != nil {
+		t.Errorf("unexpected user: %s", u.Name)
+	}
+
+	foo := r.identify("04ab")
+	if foo == nil || foo.Name != "foo" {
+		t.Fatalf("expected user foo, got %v", foo)
+	}
+
+	day := time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC) // wednesday
+
+	for _, tc := range []struct {
+		lp    int
+		ts    time.Duration
+		allow bool
+	}{
+		{1, 23 * time.Hour, false},
+		{2, 23 * time.Hour, true},
+		{2, 5 * time.Hour, true},
+		{2, 6 * time.Hour, false},
+		{2, 12*time.Hour + 30*time.Minute, true},
+		{2, 13 * time.Hour, false},
+	} {
+		if err := r.permit(foo, tc.lp, 0, day.Add(tc.ts)); (err == nil) != tc.allow {
+			t.Errorf("lp %d at %v: expected allowed %v, got %v", tc.lp, tc.ts, tc.allow, err)
+		}
+	}
+
+	bar := r.identify("05CD")
+
+	if err := r.permit(bar, 1, 9e3, day); err != nil {
+		t.Error(err)
+	}
+
+	r.consume(bar, 1, 9e3, day)
+
+	if err := r.permit(bar, 1, 1e3, day); err == nil {
+		t.Error("expected quota exhausted")
+	}
+
+	// sunday is same week
+	if err := r.permit(bar, 1, 1e3, day.AddDate(0, 0, 4)); err == nil {
+		t.Error("expected quota exhausted")
+	}
+
+	// monday starts new week
+	if err := r.permit(bar, 1, 9.5e3, day.AddDate(0, 0, 5)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUsersQuotaRunningSessions(t *testing.T) {
+	store.Instance = store.New()
+
+	cc := []UserConfig{{Name: "bar", Tags: []string{"05CD"}, Quota: 10, Period: "day"}}
+	day := time.Date(2022, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	r, err := newUsers(util.NewLogger("foo"), cc, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bar := r.identify("05CD")
+
+	if err := r.permit(bar, 1, 6e3, day); err != nil {
+		t.Error(err)
+	}
+
+	// running session at other loadpoint counts towards quota
+	if err := r.permit(bar, 2, 4e3, day); err == nil {
+		t.Error("expected quota exhausted")
+	}
+
+	r.consume(bar, 1, 6e3, day)
+
+	if err := r.permit(bar, 2, 3e3, day); err != nil {
+		t.Error(err)
+	}
+
+	// consumption is restored
+	r, err = newUsers(util.NewLogger("foo"), cc, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.permit(r.identify("05CD"), 1, 4e3, day); err == nil {
+		t.Error("expected quota exhausted after restore")
+	}
+
+	if err := r.permit(r.identify("05CD"), 1, 4e3, day.AddDate(0, 0, 1)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIdentifyUserRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	charger := &struct {
+		*mock.MockCharger
+		*mock.MockIdentifier
+	}{mock.NewMockCharger(ctrl), mock.NewMockIdentifier(ctrl)}
+
+	r, err := newUsers(util.NewLogger("foo"), []UserConfig{
+		{Name: "foo", Tags: []string{"04AB"}, Times: []string{"22:00-06:00"}},
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clck := clock.NewMock()
+	clck.Set(time.Date(2022, 1, 5, 21, 50, 0, 0, time.Local))
+
+	lp := &LoadPoint{
+		log:      util.NewLogger("foo"),
+		clock:    clck,
+		charger:  charger,
+		users:    r,
+		number:   1,
+		pushChan: make(chan push.Event, 10),
+	}
+
+	charger.MockIdentifier.EXPECT().Identify().Return("04AB", nil).AnyTimes()
+
+	// rejected before allowed times, notified once
+	lp.identifyVehicle()
+	lp.identifyVehicle()
+	if lp.user != nil || len(lp.pushChan) != 1 {
+		t.Errorf("expected single rejection, got user %v and %d notifications", lp.user, len(lp.pushChan))
+	}
+
+	// same tag authorized once allowed times have started
+	clck.Add(15 * time.Minute)
+	lp.identifyVehicle()
+	if lp.user == nil || lp.user.Name != "foo" {
+		t.Errorf("expected user authorized, got %v", lp.user)
+	}
+}
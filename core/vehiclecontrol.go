@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+)
+
+// Vehicle charge control modes
+const (
+	vehicleControlCharger = "charger" // charger only (default)
+	vehicleControlVehicle = "vehicle" // vehicle only, charger remains enabled (dumb chargers)
+	vehicleControlBoth    = "both"    // vehicle in addition to charger
+
+	vehicleControlInterval = 5 * time.Minute
+)
+
+// VehicleControlConfig defines if and how the vehicle api is used to start and stop charging
+type VehicleControlConfig struct {
+	Mode     string        `mapstructure:"mode"`     // charger, vehicle, both
+	Interval time.Duration `mapstructure:"interval"` // minimum interval between vehicle commands
+}
+
+// vehicleController sends rate-limited and de-duplicated charge commands to the vehicle api
+type vehicleController struct {
+	clock        clock.Clock
+	mode         string
+	interval     time.Duration
+	updated      time.Time // last start/stop command timestamp
+	limitUpdated time.Time // last charge limit command timestamp
+	requested    *bool     // last requested start/stop command
+	enabled      *bool     // last successful start/stop command
+	limit        int       // last successful charge limit
+}
+
+// newVehicleController creates vehicle controller from config
+func newVehicleController(clock clock.Clock, cc VehicleControlConfig) (*vehicleController, error) {
+	switch cc.Mode = strings.ToLower(cc.Mode); cc.Mode {
+	case "":
+		cc.Mode = vehicleControlCharger
+	case vehicleControlCharger, vehicleControlVehicle, vehicleControlBoth:
+	default:
+		return nil, fmt.Errorf("invalid mode: %s", cc.Mode)
+	}
+
+	if cc.Interval == 0 {
+		cc.Interval = vehicleControlInterval
+	}
+
+	return &vehicleController{
+		clock:    clock,
+		mode:     cc.Mode,
+		interval: cc.Interval,
+	}, nil
+}
+
+// active returns true if the vehicle should be used for charge control
+func (c *vehicleController) active(vehicle api.Vehicle) bool {
+	if c == nil || c.mode == vehicleControlCharger || vehicle == nil {
+		return false
+	}
+
+	_, start := vehicle.(api.VehicleStartCharge)
+	_, stop := vehicle.(api.VehicleStopCharge)
+
+	return start && stop
+}
+
+// exclusive returns true if charging is controlled by the vehicle only
+func (c *vehicleController) exclusive(vehicle api.Vehicle) bool {
+	return c.active(vehicle) && c.mode == vehicleControlVehicle
+}
+
+// waiting returns the remaining time until next command is allowed
+func (c *vehicleController) waiting(updated time.Time) time.Duration {
+	if remaining := c.interval - c.clock.Since(updated); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// enable starts or stops charging using the vehicle api.
+// It returns false if the command was rate-limited.
+func (c *vehicleController) enable(vehicle api.Vehicle, enable bool) (bool, error) {
+	c.requested = &enable
+
+	if c.enabled != nil && *c.enabled == enable {
+		return true, nil
+	}
+
+	if c.waiting(c.updated) > 0 {
+		return false, nil
+	}
+
+	c.updated = c.clock.Now()
+
+	var err error
+	if enable {
+		err = vehicle.(api.VehicleStartCharge).StartCharge()
+	} else {
+		err = vehicle.(api.VehicleStopCharge).StopCharge()
+	}
+
+	if err == nil {
+		c.enabled = &enable
+	}
+
+	return true, err
+}
+
+// sync repeats the last requested start/stop command if it has not been applied yet.
+// It returns false if nothing was sent.
+func (c *vehicleController) sync(vehicle api.Vehicle) (bool, error) {
+	if !c.active(vehicle) || c.requested == nil || c.enabled != nil && *c.enabled == *c.requested {
+		return false, nil
+	}
+
+	return c.enable(vehicle, *c.requested)
+}
+
+// setLimit sets the vehicle's on-board charge limit if supported.
+// It returns false if not supported or the command was rate-limited.
+func (c *vehicleController) setLimit(vehicle api.Vehicle, soc int) (bool, error) {
+	cl, ok := vehicle.(api.VehicleChargeLimit)
+	if c == nil || c.mode == vehicleControlCharger || !ok || soc == 0 || c.limit == soc {
+		return false, nil
+	}
+
+	if c.waiting(c.limitUpdated) > 0 {
+		return false, nil
+	}
+
+	c.limitUpdated = c.clock.Now()
+
+	err := cl.SetChargeLimit(soc)
+	if err == nil {
+		c.limit = soc
+	}
+
+	return true, err
+}
+
+// reset clears the command history, e.g. when the vehicle changes
+func (c *vehicleController) reset() {
+	if c != nil {
+		c.requested = nil
+		c.enabled = nil
+		c.limit = 0
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/evcc-io/evcc/api"
+)
+
+type remoteVehicle struct {
+	api.Vehicle
+	started, stopped, limit int
+}
+
+func (v *remoteVehicle) StartCharge() error {
+	v.started++
+	return nil
+}
+
+func (v *remoteVehicle) StopCharge() error {
+	v.stopped++
+	return nil
+}
+
+func (v *remoteVehicle) SetChargeLimit(soc int) error {
+	v.limit = soc
+	return nil
+}
+
+func TestVehicleControllerMode(t *testing.T) {
+	clck := clock.NewMock()
+	v := new(remoteVehicle)
+
+	if _, err := newVehicleController(clck, VehicleControlConfig{Mode: "foo"}); err == nil {
+		t.Error("expected invalid mode error")
+	}
+
+	c, _ := newVehicleController(clck, VehicleControlConfig{})
+	if c.active(v) {
+		t.Error("default mode must not use vehicle")
+	}
+
+	c, _ = newVehicleController(clck, VehicleControlConfig{Mode: vehicleControlVehicle})
+	if !c.active(v) || !c.exclusive(v) {
+		t.Error("vehicle mode must use vehicle exclusively")
+	}
+
+	if c.active(&struct{ api.Vehicle }{}) {
+		t.Error("vehicle without remote charge must not be used")
+	}
+}
+
+func TestVehicleControllerRateLimit(t *testing.T) {
+	clck := clock.NewMock()
+	v := new(remoteVehicle)
+	c, _ := newVehicleController(clck, VehicleControlConfig{Mode: vehicleControlBoth, Interval: time.Minute})
+
+	if sent, _ := c.enable(v, true); !sent || v.started != 1 {
+		t.Errorf("expected start, got sent %v started %d", sent, v.started)
+	}
+
+	// duplicate
+	if sent, _ := c.enable(v, true); !sent || v.started != 1 {
+		t.Errorf("expected no duplicate start, got sent %v started %d", sent, v.started)
+	}
+
+	// rate limited
+	if sent, _ := c.enable(v, false); sent || v.stopped != 0 {
+		t.Errorf("expected rate limit, got sent %v stopped %d", sent, v.stopped)
+	}
+
+	// repeated after interval
+	clck.Add(time.Minute)
+	if sent, _ := c.sync(v); !sent || v.stopped != 1 {
+		t.Errorf("expected stop, got sent %v stopped %d", sent, v.stopped)
+	}
+
+	if sent, _ := c.setLimit(v, 80); !sent || v.limit != 80 {
+		t.Errorf("expected limit, got sent %v limit %d", sent, v.limit)
+	}
+
+	if sent, _ := c.setLimit(v, 80); sent {
+		t.Error("expected no duplicate limit")
+	}
+}
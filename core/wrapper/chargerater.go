@@ -58,6 +58,35 @@ func (cr *ChargeRater) StartCharge(continued bool) {
 	}
 }
 
+// SetMeter replaces the meter. Energy charged during an ongoing session is retained.
+func (cr *ChargeRater) SetMeter(meter api.Meter) {
+	cr.Lock()
+	defer cr.Unlock()
+
+	if cr.charging {
+		// close energy of previous meter
+		if m, ok := cr.meter.(api.MeterEnergy); ok {
+			if f, err := m.TotalEnergy(); err == nil {
+				cr.chargedEnergy += f - cr.startEnergy
+			} else {
+				cr.log.ERROR.Printf("charge meter error %v", err)
+			}
+		}
+
+		cr.start = cr.clck.Now()
+
+		if m, ok := meter.(api.MeterEnergy); ok {
+			if f, err := m.TotalEnergy(); err == nil {
+				cr.startEnergy = f
+			} else {
+				cr.log.ERROR.Printf("charge meter error %v", err)
+			}
+		}
+	}
+
+	cr.meter = meter
+}
+
 // StopCharge records meter stop energy. If meter does not supply TotalEnergy,
 // stop time is recorded and accumulating energy though SetChargePower stopped.
 func (cr *ChargeRater) StopCharge() {
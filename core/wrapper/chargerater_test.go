@@ -104,3 +104,39 @@ func TestWrappedMeter(t *testing.T) {
 		t.Errorf("energy: %.1f %v", f, err)
 	}
 }
+
+func TestReplacedMeter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type EnergyDecorator struct {
+		api.Meter
+		api.MeterEnergy
+	}
+
+	me1 := mock.NewMockMeterEnergy(ctrl)
+	me2 := mock.NewMockMeterEnergy(ctrl)
+
+	cr := NewChargeRater(util.NewLogger("foo"), &EnergyDecorator{MeterEnergy: me1})
+	cr.clck = clock.NewMock()
+
+	me1.EXPECT().TotalEnergy().Return(2.0, nil)
+	cr.StartCharge(false)
+
+	// replace during session
+	me1.EXPECT().TotalEnergy().Return(3.0, nil)
+	me2.EXPECT().TotalEnergy().Return(100.0, nil)
+	cr.SetMeter(&EnergyDecorator{MeterEnergy: me2})
+
+	me2.EXPECT().TotalEnergy().Return(101.0, nil)
+	if f, err := cr.ChargedEnergy(); f != 2 || err != nil {
+		t.Errorf("energy: %.1f %v", f, err)
+	}
+
+	me2.EXPECT().TotalEnergy().Return(102.0, nil)
+	cr.StopCharge()
+
+	if f, err := cr.ChargedEnergy(); f != 3 || err != nil {
+		t.Errorf("energy: %.1f %v", f, err)
+	}
+}
@@ -39,6 +39,11 @@ const (
 	taskFroniusWeb   = "fronius-web"
 	taskTasmota      = "tasmota"
 	taskShelly       = "shelly"
+	taskVolkszaehler = "volkszähler"
+	taskEEBus        = "eebus"
+	taskShellyMdns   = "shelly-mdns"
+	taskGoEMdns      = "go-e-mdns"
+	taskUpnp         = "upnp"
 	// taskTPLink       = "tplink"
 )
 
@@ -258,6 +263,41 @@ func init() {
 		},
 	})
 
+	taskList.Add(tasks.Task{
+		ID:   taskEEBus,
+		Type: tasks.Mdns,
+		Config: map[string]interface{}{
+			"services": []string{"_ship._tcp"},
+		},
+	})
+
+	taskList.Add(tasks.Task{
+		ID:   taskShellyMdns,
+		Type: tasks.Mdns,
+		Config: map[string]interface{}{
+			"services": []string{"_http._tcp"},
+			"instance": "(?i)^shelly",
+		},
+	})
+
+	taskList.Add(tasks.Task{
+		ID:   taskGoEMdns,
+		Type: tasks.Mdns,
+		Config: map[string]interface{}{
+			"services": []string{"_http._tcp"},
+			"instance": "(?i)^go-?e",
+		},
+	})
+
+	taskList.Add(tasks.Task{
+		ID:   taskUpnp,
+		Type: tasks.Ssdp,
+		Config: map[string]interface{}{
+			"types":  []string{"upnp:rootdevice"},
+			"server": "(?i)fronius|kostal|sma|sonnen|solaredge",
+		},
+	})
+
 	// taskList.Add(tasks.Task{
 	// 	ID:      taskTPLink,
 	// 	Type:    tasks.Http,
@@ -270,7 +310,7 @@ func init() {
 	// })
 
 	taskList.Add(tasks.Task{
-		ID:      "volkszähler",
+		ID:      taskVolkszaehler,
 		Type:    tasks.Http,
 		Depends: TaskHttp,
 		Config: map[string]interface{}{
@@ -0,0 +1,83 @@
+package detect
+
+import (
+	"errors"
+	"net"
+
+	"github.com/evcc-io/evcc/util"
+	"github.com/korylprince/ipnetgen"
+)
+
+// IPsFromSubnet creates a list of ip addresses for given subnet
+func IPsFromSubnet(arg string) ([]string, error) {
+	gen, err := ipnetgen.New(arg)
+	if err != nil {
+		return nil, errors.New("could not create iterator")
+	}
+
+	var res []string
+	for ip := gen.Next(); ip != nil; ip = gen.Next() {
+		res = append(res, ip.String())
+	}
+
+	// remove network and broadcast address
+	if len(res) < 2 {
+		return nil, nil
+	}
+
+	return res[1 : len(res)-1], nil
+}
+
+// ParseHostIPNet converts host or cidr into a host list. Subnets larger than /24 are skipped.
+func ParseHostIPNet(log *util.Logger, arg string) ([]string, error) {
+	if ip := net.ParseIP(arg); ip != nil {
+		return []string{ip.String()}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(arg)
+
+	// simple host
+	if err != nil {
+		return []string{arg}, nil
+	}
+
+	// check subnet size
+	if bits, _ := ipnet.Mask.Size(); bits < 24 {
+		log.INFO.Println("skipping large subnet:", ipnet)
+		return nil, nil
+	}
+
+	return IPsFromSubnet(arg)
+}
+
+// Hosts converts hosts or cidrs into a host list. Without hosts, localhost and the local subnet are returned.
+func Hosts(log *util.Logger, args []string) ([]string, error) {
+	var hosts []string
+	for _, arg := range args {
+		res, err := ParseHostIPNet(log, arg)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, res...)
+	}
+
+	if len(hosts) > 0 {
+		return hosts, nil
+	}
+
+	// autodetect
+	ips := util.LocalIPs()
+	if len(ips) == 0 {
+		return nil, errors.New("could not find ip")
+	}
+
+	myIP := ips[0]
+	log.INFO.Println("my ip:", myIP.IP)
+
+	subnet, err := IPsFromSubnet(myIP.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{"127.0.0.1"}, subnet...), nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/evcc-io/evcc/detect/tasks"
 	"github.com/evcc-io/evcc/util"
+	"github.com/thoas/go-funk"
 )
 
 type TaskList struct {
@@ -81,6 +82,42 @@ func (l *TaskList) handler(task tasks.Task) tasks.TaskHandler {
 	return handler
 }
 
+// Reset prepares the task handlers for a new scan
+func (l *TaskList) Reset() {
+	for _, task := range l.tasks {
+		if h, ok := task.TaskHandler.(tasks.TaskResetter); ok {
+			h.Reset()
+		}
+	}
+}
+
+// browser returns true if the task discovers devices on its own and runs once per scan
+func browser(task tasks.Task) bool {
+	_, ok := task.TaskHandler.(tasks.TaskResetter)
+	return task.Depends == "" && ok
+}
+
+// Browse runs the tasks discovering devices on their own, independent of the scanned hosts.
+// If hosts are given, results for other hosts are dropped.
+func (l *TaskList) Browse(log *util.Logger, hosts []string) []tasks.Result {
+	l.once.Do(l.sort)
+
+	var all []tasks.Result
+	for _, task := range l.tasks {
+		if !browser(task) {
+			continue
+		}
+
+		for _, res := range l.Test(log, task.ID, tasks.ResultDetails{}) {
+			if len(hosts) == 0 || funk.ContainsString(hosts, res.IP) {
+				all = append(all, res)
+			}
+		}
+	}
+
+	return all
+}
+
 func (l *TaskList) Test(log *util.Logger, id string, input tasks.ResultDetails) []tasks.Result {
 	l.once.Do(l.sort)
 
@@ -112,7 +149,7 @@ func (l *TaskList) Test(log *util.Logger, id string, input tasks.ResultDetails)
 
 	// run dependent tasks
 	for _, task := range l.tasks {
-		if task.Depends == id {
+		if task.Depends == id && (id != "" || !browser(task)) {
 			// fmt.Println("task:", task)
 			for _, input := range inputs {
 				// fmt.Println("input:", input)
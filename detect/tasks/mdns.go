@@ -0,0 +1,156 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+	"github.com/grandcat/zeroconf"
+)
+
+const Mdns TaskType = "mdns"
+
+func init() {
+	registry.Add(Mdns, MdnsHandlerFactory)
+}
+
+type MdnsResult struct {
+	Service  string
+	Instance string
+	Host     string
+	Text     map[string]string `json:",omitempty"`
+}
+
+func MdnsHandlerFactory(conf map[string]interface{}) (TaskHandler, error) {
+	handler := MdnsHandler{
+		Domain:  "local.",
+		Timeout: 3 * time.Second,
+	}
+
+	err := util.DecodeOther(conf, &handler)
+
+	if err == nil && len(handler.Services) == 0 {
+		err = errors.New("missing services")
+	}
+
+	if err == nil && handler.Instance != "" {
+		handler.re, err = regexp.Compile(handler.Instance)
+	}
+
+	return &handler, err
+}
+
+// MdnsHandler browses the network for the given service types once per scan
+type MdnsHandler struct {
+	mux      sync.Mutex
+	handled  bool
+	Services []string
+	Instance string // instance name filter regex
+	Domain   string
+	Timeout  time.Duration
+	re       *regexp.Regexp
+}
+
+// text converts the TXT records to key/value pairs
+func (h *MdnsHandler) text(records []string) map[string]string {
+	if len(records) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string, len(records))
+	for _, record := range records {
+		kv := strings.SplitN(record, "=", 2)
+		if len(kv) == 2 {
+			res[strings.ToLower(kv[0])] = kv[1]
+		} else {
+			res[strings.ToLower(kv[0])] = ""
+		}
+	}
+
+	return res
+}
+
+func (h *MdnsHandler) browse(log *util.Logger, service string) (res []ResultDetails) {
+	resolver, err := zeroconf.NewResolver(nil)
+	if err != nil {
+		log.ERROR.Println("mdns:", err)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.Timeout)
+	defer cancel()
+
+	entries := make(chan *zeroconf.ServiceEntry)
+	if err := resolver.Browse(ctx, service, h.Domain, entries); err != nil {
+		log.ERROR.Println("mdns:", err)
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return res
+
+		case entry := <-entries:
+			if entry == nil || len(entry.AddrIPv4) == 0 {
+				continue
+			}
+
+			if h.re != nil && !h.re.MatchString(entry.Instance) {
+				continue
+			}
+
+			res = append(res, ResultDetails{
+				IP:   entry.AddrIPv4[0].String(),
+				Port: entry.Port,
+				MdnsResult: &MdnsResult{
+					Service:  service,
+					Instance: entry.Instance,
+					Host:     strings.TrimSuffix(entry.HostName, "."),
+					Text:     h.text(entry.Text),
+				},
+			})
+		}
+	}
+}
+
+// Reset allows the handler to run again on the next scan
+func (h *MdnsHandler) Reset() {
+	h.mux.Lock()
+	h.handled = false
+	h.mux.Unlock()
+}
+
+func (h *MdnsHandler) Test(log *util.Logger, in ResultDetails) (res []ResultDetails) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.handled {
+		return nil
+	}
+	h.handled = true
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, service := range h.Services {
+		wg.Add(1)
+		go func(service string) {
+			defer wg.Done()
+
+			found := h.browse(log, service)
+
+			mu.Lock()
+			res = append(res, found...)
+			mu.Unlock()
+		}(service)
+	}
+
+	wg.Wait()
+
+	return res
+}
@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+	"github.com/grandcat/zeroconf"
+)
+
+func TestMdns(t *testing.T) {
+	server, err := zeroconf.Register("evcc-test", "_evcctest._tcp", "local.", 4711, []string{"ski=1234abcd"}, nil)
+	if err != nil {
+		t.Skip("mdns not available:", err)
+	}
+	defer server.Shutdown()
+
+	h, err := MdnsHandlerFactory(map[string]interface{}{
+		"services": []string{"_evcctest._tcp"},
+		"instance": "^evcc",
+		"timeout":  2 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := h.Test(util.NewLogger("foo"), ResultDetails{})
+	if len(res) == 0 {
+		t.Skip("mdns responder not reachable")
+	}
+
+	r := res[0]
+	if net.ParseIP(r.IP) == nil || r.Port != 4711 || r.MdnsResult == nil {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+
+	if r.MdnsResult.Instance != "evcc-test" || r.MdnsResult.Text["ski"] != "1234abcd" {
+		t.Errorf("unexpected mdns result: %+v", r.MdnsResult)
+	}
+
+	// discovery runs once per scan
+	if res := h.Test(util.NewLogger("foo"), ResultDetails{}); len(res) != 0 {
+		t.Errorf("unexpected repeated result: %+v", res)
+	}
+
+	// next scan
+	h.(*MdnsHandler).Reset()
+	if res := h.Test(util.NewLogger("foo"), ResultDetails{}); len(res) == 0 {
+		t.Error("expected result on next scan")
+	}
+}
+
+func TestMdnsInstanceFilter(t *testing.T) {
+	server, err := zeroconf.Register("evcc-test", "_evcctest._tcp", "local.", 4711, nil, nil)
+	if err != nil {
+		t.Skip("mdns not available:", err)
+	}
+	defer server.Shutdown()
+
+	h, err := MdnsHandlerFactory(map[string]interface{}{
+		"services": []string{"_evcctest._tcp"},
+		"instance": "^shelly",
+		"timeout":  time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := h.Test(util.NewLogger("foo"), ResultDetails{}); len(res) != 0 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+	"github.com/koron/go-ssdp"
+)
+
+const Ssdp TaskType = "ssdp"
+
+func init() {
+	registry.Add(Ssdp, SsdpHandlerFactory)
+}
+
+type SsdpResult struct {
+	Type     string
+	USN      string
+	Location string
+	Server   string `json:",omitempty"`
+}
+
+func SsdpHandlerFactory(conf map[string]interface{}) (TaskHandler, error) {
+	handler := SsdpHandler{
+		Timeout: 2 * time.Second,
+	}
+
+	err := util.DecodeOther(conf, &handler)
+
+	if err == nil && len(handler.Types) == 0 {
+		err = errors.New("missing types")
+	}
+
+	if err == nil && handler.Server != "" {
+		handler.re, err = regexp.Compile(handler.Server)
+	}
+
+	return &handler, err
+}
+
+// SsdpHandler searches the network for the given search targets once per scan
+type SsdpHandler struct {
+	mux     sync.Mutex
+	handled bool
+	Types   []string
+	Server  string // server header filter regex
+	Timeout time.Duration
+	re      *regexp.Regexp
+}
+
+// address extracts host and port from the service location
+func (h *SsdpHandler) address(location string) (string, int, bool) {
+	u, err := url.Parse(location)
+	if err != nil || u.Hostname() == "" || net.ParseIP(u.Hostname()) == nil {
+		return "", 0, false
+	}
+
+	port, _ := strconv.Atoi(u.Port())
+
+	return u.Hostname(), port, true
+}
+
+// Reset allows the handler to run again on the next scan
+func (h *SsdpHandler) Reset() {
+	h.mux.Lock()
+	h.handled = false
+	h.mux.Unlock()
+}
+
+func (h *SsdpHandler) Test(log *util.Logger, in ResultDetails) (res []ResultDetails) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.handled {
+		return nil
+	}
+	h.handled = true
+
+	// devices usually respond to multiple search targets
+	seen := make(map[string]bool)
+
+	waitSec := int(h.Timeout / time.Second)
+	if waitSec < 1 {
+		waitSec = 1
+	}
+
+	for _, typ := range h.Types {
+		list, err := ssdp.Search(typ, waitSec, "")
+		if err != nil {
+			log.ERROR.Println("ssdp:", err)
+			continue
+		}
+
+		for _, srv := range list {
+			if seen[srv.USN] || h.re != nil && !h.re.MatchString(srv.Server) {
+				continue
+			}
+
+			ip, port, ok := h.address(srv.Location)
+			if !ok {
+				continue
+			}
+
+			seen[srv.USN] = true
+
+			res = append(res, ResultDetails{
+				IP:   ip,
+				Port: port,
+				SsdpResult: &SsdpResult{
+					Type:     srv.Type,
+					USN:      srv.USN,
+					Location: srv.Location,
+					Server:   srv.Server,
+				},
+			})
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,57 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+	"github.com/koron/go-ssdp"
+)
+
+func TestSsdp(t *testing.T) {
+	ad, err := ssdp.Advertise("urn:evcc-io:device:Test:1", "uuid:evcc-test", "http://127.0.0.1:8080/desc.xml", "Linux UPnP/1.0 Fronius/1.0", 1800)
+	if err != nil {
+		t.Skip("ssdp not available:", err)
+	}
+	defer ad.Close()
+
+	h, err := SsdpHandlerFactory(map[string]interface{}{
+		"types":   []string{"urn:evcc-io:device:Test:1"},
+		"server":  "(?i)fronius",
+		"timeout": time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := h.Test(util.NewLogger("foo"), ResultDetails{})
+	if len(res) == 0 {
+		t.Skip("ssdp responder not reachable")
+	}
+
+	r := res[0]
+	if r.IP != "127.0.0.1" || r.Port != 8080 || r.SsdpResult == nil || r.SsdpResult.USN != "uuid:evcc-test" {
+		t.Errorf("unexpected result: %+v %+v", r, r.SsdpResult)
+	}
+}
+
+func TestSsdpAddress(t *testing.T) {
+	var h SsdpHandler
+
+	for _, tc := range []struct {
+		location string
+		ip       string
+		port     int
+		ok       bool
+	}{
+		{"http://192.0.2.2:49000/desc.xml", "192.0.2.2", 49000, true},
+		{"http://192.0.2.2/desc.xml", "192.0.2.2", 0, true},
+		{"http://fritz.box/desc.xml", "", 0, false},
+		{"", "", 0, false},
+	} {
+		ip, port, ok := h.address(tc.location)
+		if ip != tc.ip || port != tc.port || ok != tc.ok {
+			t.Errorf("%s: unexpected %s %d %v", tc.location, ip, port, ok)
+		}
+	}
+}
@@ -12,6 +12,8 @@ type ResultDetails struct {
 	ModbusResult *ModbusResult `json:",omitempty"`
 	KebaResult   *KebaResult   `json:",omitempty"`
 	SmaResult    *SmaResult    `json:",omitempty"`
+	MdnsResult   *MdnsResult   `json:",omitempty"`
+	SsdpResult   *SsdpResult   `json:",omitempty"`
 }
 
 func (d *ResultDetails) Clone() ResultDetails {
@@ -41,3 +43,8 @@ type Task struct {
 type TaskHandler interface {
 	Test(log *util.Logger, in ResultDetails) []ResultDetails
 }
+
+// TaskResetter is implemented by handlers that run only once per scan
+type TaskResetter interface {
+	Reset()
+}
@@ -0,0 +1,153 @@
+package detect
+
+import (
+	"strings"
+
+	"github.com/evcc-io/evcc/detect/tasks"
+	"github.com/evcc-io/evcc/util/templates"
+)
+
+// Suggestion is a device template with parameters prefilled from a detection result
+type Suggestion struct {
+	Class    string                 `json:"class"`
+	Template string                 `json:"template"`
+	Usage    string                 `json:"usage,omitempty"`
+	IP       string                 `json:"ip"`
+	Task     string                 `json:"task"`
+	Values   map[string]interface{} `json:"values"`
+}
+
+// mapping maps a detection task to its device template
+type mapping struct {
+	class, template string
+	usages          []string
+	values          func(tasks.Result) map[string]interface{}
+}
+
+func host(res tasks.Result) map[string]interface{} {
+	return map[string]interface{}{
+		"host": res.ResultDetails.IP,
+	}
+}
+
+func hostPort(res tasks.Result) map[string]interface{} {
+	return map[string]interface{}{
+		"host": res.ResultDetails.IP,
+		"port": res.ResultDetails.Port,
+	}
+}
+
+func sunspec(res tasks.Result) map[string]interface{} {
+	values := map[string]interface{}{
+		templates.ParamModbus:         templates.ModbusKeyTCPIP,
+		templates.ModbusParamNameHost: res.ResultDetails.IP,
+		templates.ModbusParamNamePort: res.ResultDetails.Port,
+		templates.ModbusParamNameId:   1,
+	}
+
+	if res.ModbusResult != nil {
+		values[templates.ModbusParamNameId] = res.ModbusResult.SlaveID
+	}
+
+	return values
+}
+
+func keba(res tasks.Result) map[string]interface{} {
+	values := host(res)
+	if res.KebaResult != nil && res.KebaResult.Serial != "" {
+		values["serial"] = res.KebaResult.Serial
+	}
+	return values
+}
+
+func eebus(res tasks.Result) map[string]interface{} {
+	values := make(map[string]interface{})
+	if res.MdnsResult != nil && res.MdnsResult.Text["ski"] != "" {
+		values["ski"] = res.MdnsResult.Text["ski"]
+	}
+	return values
+}
+
+var (
+	allUsages = []string{templates.UsageChoiceGrid, templates.UsageChoicePV, templates.UsageChoiceBattery}
+
+	// mappings are the device templates per detection task
+	mappings = map[string][]mapping{
+		taskInverter:     {{templates.Meter, "sunspec", []string{templates.UsageChoicePV}, sunspec}},
+		taskBattery:      {{templates.Meter, "sunspec", []string{templates.UsageChoiceBattery}, sunspec}},
+		taskMeter:        {{templates.Meter, "sunspec", []string{templates.UsageChoiceGrid}, sunspec}},
+		taskE3DC:         {{templates.Meter, "e3dc", allUsages, hostPort}},
+		taskSonnen:       {{templates.Meter, "sonnenbatterie-eco10", allUsages, host}},
+		taskPowerwall:    {{templates.Meter, "tesla-powerwall", allUsages, host}},
+		taskFroniusWeb:   {{templates.Meter, "fronius-solarapi-v1", []string{templates.UsageChoicePV, templates.UsageChoiceGrid}, host}},
+		taskVolkszaehler: {{templates.Meter, "volkszaehler-http", []string{templates.UsageChoiceGrid}, hostPort}},
+		taskKEBA:         {{templates.Charger, "keba", nil, keba}},
+		taskWallbe:       {{templates.Charger, "wallbe", nil, hostPort}},
+		taskPhoenixEMEth: {{templates.Charger, "phoenix-em-eth", nil, hostPort}},
+		taskPhoenixEVEth: {{templates.Charger, "phoenix-ev-eth", nil, hostPort}},
+		taskEVSEWifi:     {{templates.Charger, "evsewifi", nil, host}},
+		taskGoE:          {{templates.Charger, "go-e", nil, host}},
+		taskOpenwb:       {{templates.Charger, "openwb", nil, host}},
+		taskShelly:       {{templates.Charger, "shelly", nil, host}},
+		taskShellyMdns:   {{templates.Charger, "shelly", nil, host}},
+		taskGoEMdns:      {{templates.Charger, "go-e", nil, host}},
+		taskEEBus:        {{templates.Charger, "eebus", nil, eebus}},
+		taskTasmota: {
+			{templates.Charger, "tasmota", nil, host},
+			{templates.Meter, "tasmota", []string{templates.UsageChoiceGrid, templates.UsageChoicePV, templates.UsageChoiceCharge}, host},
+		},
+	}
+)
+
+// smaMapping distinguishes SMA energy meters from inverters providing a web interface
+func smaMapping(res tasks.Result) mapping {
+	if res.SmaResult != nil && res.SmaResult.Http {
+		return mapping{templates.Meter, "sma-inverter", []string{templates.UsageChoicePV, templates.UsageChoiceBattery}, host}
+	}
+	return mapping{templates.Meter, "sma-home-manager", []string{templates.UsageChoiceGrid}, host}
+}
+
+// Suggest maps detection results to device templates. Meter templates are suggested once per usage.
+// Devices found by multiple tasks are suggested only once.
+func Suggest(res []tasks.Result) []Suggestion {
+	var suggestions []Suggestion
+	seen := make(map[string]bool)
+
+	for _, hit := range res {
+		list := mappings[hit.ID]
+		if hit.ID == taskSMA {
+			list = []mapping{smaMapping(hit)}
+		}
+
+		for _, m := range list {
+			usages := m.usages
+			if len(usages) == 0 {
+				usages = []string{""}
+			}
+
+			for _, usage := range usages {
+				key := strings.Join([]string{m.class, m.template, usage, hit.ResultDetails.IP}, "/")
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				values := m.values(hit)
+				if usage != "" {
+					values[templates.ParamUsage] = usage
+				}
+
+				suggestions = append(suggestions, Suggestion{
+					Class:    m.class,
+					Template: m.template,
+					Usage:    usage,
+					IP:       hit.ResultDetails.IP,
+					Task:     hit.ID,
+					Values:   values,
+				})
+			}
+		}
+	}
+
+	return suggestions
+}
@@ -0,0 +1,102 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/evcc-io/evcc/detect/tasks"
+	"github.com/evcc-io/evcc/util/templates"
+)
+
+func TestSuggestTemplates(t *testing.T) {
+	hit := tasks.Result{
+		ResultDetails: tasks.ResultDetails{
+			IP:           "192.0.2.2",
+			Port:         502,
+			ModbusResult: &tasks.ModbusResult{SlaveID: 126},
+			KebaResult:   &tasks.KebaResult{Serial: "12345"},
+			MdnsResult:   &tasks.MdnsResult{Text: map[string]string{"ski": "1234abcd"}},
+		},
+	}
+
+	var res []tasks.Result
+	for id := range mappings {
+		hit.ID = id
+		res = append(res, hit)
+	}
+
+	hit.ID = taskSMA
+	res = append(res, hit)
+
+	for _, s := range Suggest(res) {
+		tmpl, err := templates.ByTemplate(s.Template, s.Class)
+		if err != nil {
+			t.Errorf("%s: %v", s.Task, err)
+			continue
+		}
+
+		if s.Usage != "" && !contains(tmpl.Usages(), s.Usage) {
+			t.Errorf("%s: template %s does not support usage %s", s.Task, s.Template, s.Usage)
+		}
+
+		if _, _, err := tmpl.RenderResult(templates.TemplateRenderModeInstance, s.Values); err != nil {
+			t.Errorf("%s: template %s: %v", s.Task, s.Template, err)
+		}
+	}
+}
+
+func TestSuggestUnique(t *testing.T) {
+	res := Suggest([]tasks.Result{
+		{Task: tasks.Task{ID: taskShelly}, ResultDetails: tasks.ResultDetails{IP: "192.0.2.2"}},
+		{Task: tasks.Task{ID: taskShellyMdns}, ResultDetails: tasks.ResultDetails{IP: "192.0.2.2"}},
+		{Task: tasks.Task{ID: taskShellyMdns}, ResultDetails: tasks.ResultDetails{IP: "192.0.2.3"}},
+	})
+
+	if len(res) != 2 {
+		t.Fatalf("expected 2 suggestions, got %v", res)
+	}
+}
+
+func TestSuggestEEBus(t *testing.T) {
+	res := Suggest([]tasks.Result{{
+		Task: tasks.Task{ID: taskEEBus},
+		ResultDetails: tasks.ResultDetails{
+			IP:         "192.0.2.2",
+			Port:       4711,
+			MdnsResult: &tasks.MdnsResult{Text: map[string]string{"ski": "1234abcd"}},
+		},
+	}})
+
+	if len(res) != 1 || res[0].Template != "eebus" || res[0].Values["ski"] != "1234abcd" {
+		t.Errorf("unexpected suggestions: %v", res)
+	}
+}
+
+func TestSuggestSunspec(t *testing.T) {
+	res := Suggest([]tasks.Result{{
+		Task: tasks.Task{ID: taskInverter},
+		ResultDetails: tasks.ResultDetails{
+			IP:           "192.0.2.2",
+			Port:         1502,
+			ModbusResult: &tasks.ModbusResult{SlaveID: 126},
+		},
+	}})
+
+	if len(res) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d", len(res))
+	}
+
+	s := res[0]
+	if s.Template != "sunspec" || s.Usage != templates.UsageChoicePV ||
+		s.Values["port"] != 1502 || s.Values["id"] != uint8(126) || s.Values["host"] != "192.0.2.2" {
+		t.Errorf("unexpected suggestion: %+v", s)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -46,9 +46,13 @@ func Work(log *util.Logger, num int, hosts []string) []tasks.Result {
 	// 		),
 	// )
 
+	taskList.Reset()
+
+	// browse tasks run once per scan, independent of host reachability
+	res := taskList.Browse(log, hosts)
+
 	wg := workers(log, num, ip, hits)
 
-	var res []tasks.Result
 	go func() {
 		for hits := range hits {
 			res = append(res, hits...)
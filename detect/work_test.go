@@ -0,0 +1,76 @@
+package detect
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/evcc-io/evcc/detect/tasks"
+	"github.com/evcc-io/evcc/util"
+)
+
+// onceHandler behaves like the mdns and ssdp handlers and runs once per scan
+type onceHandler struct {
+	mux     sync.Mutex
+	handled bool
+}
+
+func (h *onceHandler) Reset() {
+	h.mux.Lock()
+	h.handled = false
+	h.mux.Unlock()
+}
+
+func (h *onceHandler) Test(log *util.Logger, in tasks.ResultDetails) []tasks.ResultDetails {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.handled {
+		return nil
+	}
+	h.handled = true
+
+	return []tasks.ResultDetails{{IP: "127.0.0.2"}, {IP: "192.0.2.2"}}
+}
+
+func TestWorkRepeatedScan(t *testing.T) {
+	defer func(tl *TaskList) { taskList = tl }(taskList)
+
+	taskList = &TaskList{tasks: []tasks.Task{
+		{ID: "once", Type: "once", TaskHandler: new(onceHandler)},
+	}}
+
+	log := util.NewLogger("foo")
+	hosts := []string{"127.0.0.1", "127.0.0.2"}
+
+	// results of other hosts are dropped
+	for scan := 1; scan <= 2; scan++ {
+		if res := Work(log, 2, hosts); len(res) != 1 || res[0].IP != "127.0.0.2" {
+			t.Errorf("scan %d: expected 1 result, got %v", scan, res)
+		}
+	}
+
+	// browsing without hosts
+	if res := Work(log, 2, nil); len(res) != 2 {
+		t.Errorf("expected 2 results, got %v", res)
+	}
+}
+
+func TestWorkBrowseWithoutPing(t *testing.T) {
+	defer func(tl *TaskList) { taskList = tl }(taskList)
+
+	taskList = &TaskList{tasks: []tasks.Task{
+		{ID: TaskPing, Type: tasks.Ping, TaskHandler: new(failHandler)},
+		{ID: "once", Type: "once", TaskHandler: new(onceHandler)},
+	}}
+
+	if res := Work(util.NewLogger("foo"), 2, []string{"127.0.0.2"}); len(res) != 1 || res[0].ID != "once" {
+		t.Errorf("expected browse result, got %v", res)
+	}
+}
+
+// failHandler never finds a device
+type failHandler struct{}
+
+func (h *failHandler) Test(log *util.Logger, in tasks.ResultDetails) []tasks.ResultDetails {
+	return nil
+}
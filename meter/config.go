@@ -26,6 +26,12 @@ func (r meterRegistry) Get(name string) (func(map[string]interface{}) (api.Meter
 
 var registry meterRegistry = make(map[string]func(map[string]interface{}) (api.Meter, error))
 
+// Registered returns true if the meter type is registered
+func Registered(typ string) bool {
+	_, err := registry.Get(strings.ToLower(typ))
+	return err == nil
+}
+
 // NewFromConfig creates meter from configuration
 func NewFromConfig(typ string, other map[string]interface{}) (v api.Meter, err error) {
 	factory, err := registry.Get(strings.ToLower(typ))
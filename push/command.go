@@ -0,0 +1,259 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/loadpoint"
+)
+
+// StatusEvent is the event template used for rendering status replies
+const StatusEvent = "status"
+
+// defaultEvents are the templates for command replies unless configured
+var defaultEvents = map[string]EventTemplateConfig{
+	StatusEvent: {
+		Title: "${title}",
+		Msg:   "Mode ${mode}, charging ${chargePower:%.1fk}kW{{ if .vehicleTitle }}, vehicle {{ .vehicleTitle }}{{ end }}, min soc ${minSoC}%, target soc ${targetSoC}%",
+	},
+}
+
+// Controller is implemented by messengers accepting remote control commands
+type Controller interface {
+	Control(cmd *Commander)
+}
+
+// Choice is a command offered to the user, e.g. as button
+type Choice struct {
+	Label, Command string
+}
+
+// Reply is the result of a remote control command
+type Reply struct {
+	Text    string
+	Choices []Choice
+}
+
+// Commander executes remote control commands on the loadpoints
+type Commander struct {
+	lps    []loadpoint.API
+	render func(Event) (string, string, error)
+}
+
+// NewCommander creates a commander for the loadpoints rendering status replies with the given function
+func NewCommander(lps []loadpoint.API, render func(Event) (string, string, error)) *Commander {
+	return &Commander{
+		lps:    lps,
+		render: render,
+	}
+}
+
+// commands lists the supported commands
+const commands = `/status - show loadpoint status
+/mode [lp] <off|now|minpv|pv> - set charge mode
+/target [lp] <soc> <hh:mm>|off - set or remove target charge
+/minsoc [lp] <soc> - set minimum soc`
+
+// Execute executes the command and returns the reply
+func (c *Commander) Execute(text string) (Reply, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return Reply{Text: commands}, nil
+	}
+
+	// strip bot name from group commands, e.g. /status@evccbot
+	cmd := strings.ToLower(strings.SplitN(fields[0], "@", 2)[0])
+	args := fields[1:]
+
+	switch cmd {
+	case "/status":
+		return c.status()
+	case "/mode":
+		return c.withLoadpoint(cmd, args, 1, c.mode)
+	case "/target":
+		return c.withLoadpoint(cmd, args, 2, c.target)
+	case "/minsoc":
+		return c.withLoadpoint(cmd, args, 1, c.minSoC)
+	default:
+		return Reply{Text: commands}, nil
+	}
+}
+
+// withLoadpoint resolves the loadpoint argument. It may be omitted if there is only a single loadpoint.
+func (c *Commander) withLoadpoint(cmd string, args []string, max int, fun func(string, int, loadpoint.API, []string) (Reply, error)) (Reply, error) {
+	if len(c.lps) == 0 {
+		return Reply{}, errors.New("no loadpoints")
+	}
+
+	if len(c.lps) == 1 && len(args) <= max {
+		return fun(cmd, 1, c.lps[0], args)
+	}
+
+	if len(args) == 0 {
+		res := Reply{Text: "Select loadpoint"}
+		for id, lp := range c.lps {
+			res.Choices = append(res.Choices, Choice{
+				Label:   c.title(id, lp),
+				Command: fmt.Sprintf("%s %d", cmd, id+1),
+			})
+		}
+		return res, nil
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 1 || id > len(c.lps) {
+		return Reply{}, fmt.Errorf("invalid loadpoint: %s", args[0])
+	}
+
+	return fun(cmd, id, c.lps[id-1], args[1:])
+}
+
+// title returns the loadpoint's name for display
+func (c *Commander) title(id int, lp loadpoint.API) string {
+	if name := lp.Name(); name != "" {
+		return name
+	}
+	return fmt.Sprintf("Loadpoint %d", id+1)
+}
+
+// status renders the status of all loadpoints
+func (c *Commander) status() (Reply, error) {
+	var res []string
+	var choices []Choice
+
+	for id := range c.lps {
+		id := id
+
+		title, msg, err := c.render(Event{Event: StatusEvent, LoadPoint: &id})
+		if err != nil {
+			return Reply{}, err
+		}
+
+		res = append(res, strings.TrimSpace(title+"\n"+msg))
+
+		mode, minSoC := "/mode", "/minsoc"
+		if len(c.lps) > 1 {
+			mode, minSoC = fmt.Sprintf("/mode %d", id+1), fmt.Sprintf("/minsoc %d", id+1)
+		}
+
+		choices = append(choices, Choice{Label: "Mode", Command: mode}, Choice{Label: "Min SoC", Command: minSoC})
+	}
+
+	return Reply{Text: strings.Join(res, "\n\n"), Choices: choices}, nil
+}
+
+// mode sets the charge mode or offers the available modes
+func (c *Commander) mode(cmd string, id int, lp loadpoint.API, args []string) (Reply, error) {
+	if len(args) == 0 {
+		res := Reply{Text: fmt.Sprintf("Current mode: %s", lp.GetMode())}
+		for _, mode := range []api.ChargeMode{api.ModeOff, api.ModeNow, api.ModeMinPV, api.ModePV} {
+			res.Choices = append(res.Choices, Choice{
+				Label:   string(mode),
+				Command: fmt.Sprintf("%s %d %s", cmd, id, mode),
+			})
+		}
+		return res, nil
+	}
+
+	mode, err := api.ChargeModeString(args[0])
+	if err != nil || mode == api.ModeEmpty {
+		return Reply{}, fmt.Errorf("invalid mode: %s", args[0])
+	}
+
+	lp.SetMode(mode)
+
+	return Reply{Text: fmt.Sprintf("%s: mode set to %s", c.title(id-1, lp), mode)}, nil
+}
+
+// parseSoC parses a soc percentage
+func parseSoC(s string) (int, error) {
+	soc, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil || soc < 0 || soc > 100 {
+		return 0, fmt.Errorf("invalid soc: %s", s)
+	}
+	return soc, nil
+}
+
+// minSoC sets the minimum soc or offers common values
+func (c *Commander) minSoC(cmd string, id int, lp loadpoint.API, args []string) (Reply, error) {
+	if len(args) == 0 {
+		res := Reply{Text: fmt.Sprintf("Current minimum soc: %d%%", lp.GetMinSoC())}
+		for _, soc := range []int{0, 10, 20, 30, 50} {
+			res.Choices = append(res.Choices, Choice{
+				Label:   fmt.Sprintf("%d%%", soc),
+				Command: fmt.Sprintf("%s %d %d", cmd, id, soc),
+			})
+		}
+		return res, nil
+	}
+
+	soc, err := parseSoC(args[0])
+	if err != nil {
+		return Reply{}, err
+	}
+
+	lp.SetMinSoC(soc)
+
+	return Reply{Text: fmt.Sprintf("%s: minimum soc set to %d%%", c.title(id-1, lp), soc)}, nil
+}
+
+// timezone returns the configured local timezone
+func timezone() *time.Location {
+	tz := os.Getenv("TZ")
+	if tz == "" {
+		tz = "Local"
+	}
+
+	loc, _ := time.LoadLocation(tz)
+	return loc
+}
+
+// targetTime returns the next occurrence of the hh:mm time of day
+func targetTime(s string, now time.Time) (time.Time, error) {
+	hm, err := time.Parse("15:04", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s", s)
+	}
+
+	ts := time.Date(now.Year(), now.Month(), now.Day(), hm.Hour(), hm.Minute(), 0, 0, now.Location())
+	if !ts.After(now) {
+		ts = ts.AddDate(0, 0, 1)
+	}
+
+	return ts, nil
+}
+
+// target sets or removes the target charge
+func (c *Commander) target(cmd string, id int, lp loadpoint.API, args []string) (Reply, error) {
+	if len(args) == 1 && strings.EqualFold(args[0], "off") {
+		lp.SetTargetCharge(time.Time{}, 0)
+		return Reply{Text: fmt.Sprintf("%s: target charge removed", c.title(id-1, lp))}, nil
+	}
+
+	if len(args) != 2 {
+		text := "No target charge"
+		if ts := lp.GetTargetTime(); !ts.IsZero() {
+			text = fmt.Sprintf("Target charge: %d%% at %s", lp.GetTargetSoC(), ts.Format("Mon 15:04"))
+		}
+		return Reply{Text: text + "\nUsage: /target [lp] <soc> <hh:mm>|off"}, nil
+	}
+
+	soc, err := parseSoC(args[0])
+	if err != nil {
+		return Reply{}, err
+	}
+
+	ts, err := targetTime(args[1], time.Now().In(timezone()))
+	if err != nil {
+		return Reply{}, err
+	}
+
+	lp.SetTargetCharge(ts, soc)
+
+	return Reply{Text: fmt.Sprintf("%s: target charge %d%% at %s", c.title(id-1, lp), soc, ts.Format("Mon 15:04"))}, nil
+}
@@ -0,0 +1,151 @@
+package push
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/util"
+)
+
+type testLoadpoint struct {
+	loadpoint.API
+	name       string
+	mode       api.ChargeMode
+	minSoC     int
+	targetSoC  int
+	targetTime time.Time
+}
+
+func (lp *testLoadpoint) Name() string                { return lp.name }
+func (lp *testLoadpoint) GetMode() api.ChargeMode     { return lp.mode }
+func (lp *testLoadpoint) SetMode(mode api.ChargeMode) { lp.mode = mode }
+func (lp *testLoadpoint) GetMinSoC() int              { return lp.minSoC }
+func (lp *testLoadpoint) SetMinSoC(soc int)           { lp.minSoC = soc }
+func (lp *testLoadpoint) GetTargetSoC() int           { return lp.targetSoC }
+func (lp *testLoadpoint) GetTargetTime() time.Time    { return lp.targetTime }
+
+func (lp *testLoadpoint) SetTargetCharge(ts time.Time, soc int) {
+	lp.targetTime, lp.targetSoC = ts, soc
+}
+
+func TestCommandSingleLoadpoint(t *testing.T) {
+	lp := &testLoadpoint{name: "garage", mode: api.ModePV}
+	cmd := NewCommander([]loadpoint.API{lp}, nil)
+
+	res, err := cmd.Execute("/mode")
+	if err != nil || len(res.Choices) != 4 || res.Choices[1].Command != "/mode 1 now" {
+		t.Fatalf("unexpected reply: %+v %v", res, err)
+	}
+
+	// button command includes loadpoint
+	if _, err := cmd.Execute(res.Choices[1].Command); err != nil || lp.mode != api.ModeNow {
+		t.Errorf("unexpected mode: %s %v", lp.mode, err)
+	}
+
+	if _, err := cmd.Execute("/mode@evccbot minpv"); err != nil || lp.mode != api.ModeMinPV {
+		t.Errorf("unexpected mode: %s %v", lp.mode, err)
+	}
+
+	if _, err := cmd.Execute("/mode foo"); err == nil {
+		t.Error("expected invalid mode error")
+	}
+
+	if _, err := cmd.Execute("/minsoc 30%"); err != nil || lp.minSoC != 30 {
+		t.Errorf("unexpected min soc: %d %v", lp.minSoC, err)
+	}
+
+	if _, err := cmd.Execute("/minsoc 120"); err == nil {
+		t.Error("expected invalid soc error")
+	}
+
+	if _, err := cmd.Execute("/target 80 7:30"); err != nil || lp.targetSoC != 80 || lp.targetTime.IsZero() {
+		t.Errorf("unexpected target: %d %v %v", lp.targetSoC, lp.targetTime, err)
+	}
+
+	if _, err := cmd.Execute("/target off"); err != nil || !lp.targetTime.IsZero() {
+		t.Errorf("unexpected target: %v %v", lp.targetTime, err)
+	}
+
+	if res, _ := cmd.Execute("/help"); !strings.Contains(res.Text, "/status") {
+		t.Errorf("unexpected help: %s", res.Text)
+	}
+}
+
+func TestCommandMultipleLoadpoints(t *testing.T) {
+	lp1 := &testLoadpoint{name: "garage", mode: api.ModePV}
+	lp2 := &testLoadpoint{mode: api.ModePV}
+	cmd := NewCommander([]loadpoint.API{lp1, lp2}, nil)
+
+	res, err := cmd.Execute("/mode")
+	if err != nil || len(res.Choices) != 2 || res.Choices[0].Label != "garage" || res.Choices[1].Label != "Loadpoint 2" {
+		t.Fatalf("unexpected reply: %+v %v", res, err)
+	}
+
+	if _, err := cmd.Execute("/mode 2 off"); err != nil || lp2.mode != api.ModeOff || lp1.mode != api.ModePV {
+		t.Errorf("unexpected modes: %s %s %v", lp1.mode, lp2.mode, err)
+	}
+
+	if _, err := cmd.Execute("/mode 3 off"); err == nil {
+		t.Error("expected invalid loadpoint error")
+	}
+}
+
+func TestCommandStatus(t *testing.T) {
+	cache := util.NewCache()
+	for id, mode := range []api.ChargeMode{api.ModePV, api.ModeNow} {
+		id := id
+		p := util.Param{LoadPoint: &id, Key: "mode", Val: mode}
+		cache.Add(p.UniqueID(), p)
+	}
+
+	hub, err := NewHub(map[string]EventTemplateConfig{
+		StatusEvent: {Title: "Status", Msg: "${mode}"},
+	}, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCommander([]loadpoint.API{&testLoadpoint{}, &testLoadpoint{}}, hub.Render)
+
+	res, err := cmd.Execute("/status")
+	if err != nil || res.Text != "Status\npv\n\nStatus\nnow" || len(res.Choices) != 4 || res.Choices[2].Command != "/mode 2" {
+		t.Fatalf("unexpected reply: %+v %v", res, err)
+	}
+
+	// default template
+	id := 0
+	for k, v := range map[string]interface{}{"title": "Garage", "chargePower": 3700.0, "minSoC": 20, "targetSoC": 80, "vehicleTitle": ""} {
+		p := util.Param{LoadPoint: &id, Key: k, Val: v}
+		cache.Add(p.UniqueID(), p)
+	}
+
+	hub, _ = NewHub(nil, cache)
+	title, msg, err := hub.Render(Event{Event: StatusEvent, LoadPoint: &id})
+	if err != nil || title != "Garage" || msg != "Mode pv, charging 3.7kW, min soc 20%, target soc 80%" {
+		t.Errorf("unexpected default status: %s %s %v", title, msg, err)
+	}
+}
+
+func TestTargetTime(t *testing.T) {
+	now := time.Date(2021, 11, 19, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		in  string
+		out time.Time
+	}{
+		{"13:30", time.Date(2021, 11, 19, 13, 30, 0, 0, time.UTC)},
+		{"7:00", time.Date(2021, 11, 20, 7, 0, 0, 0, time.UTC)},
+		{"12:00", time.Date(2021, 11, 20, 12, 0, 0, 0, time.UTC)},
+	} {
+		if ts, err := targetTime(tc.in, now); err != nil || !ts.Equal(tc.out) {
+			t.Errorf("%s: expected %v, got %v %v", tc.in, tc.out, ts, err)
+		}
+	}
+
+	if _, err := targetTime("25:00", now); err == nil {
+		t.Error("expected invalid time error")
+	}
+}
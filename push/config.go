@@ -12,6 +12,22 @@ type Sender interface {
 	Send(title, msg string)
 }
 
+// Message is a push event with its rendered title and message
+type Message struct {
+	Event      string                 `json:"event"`
+	LoadPoint  *int                   `json:"loadpoint,omitempty"` // loadpoint number starting at 1
+	Title      string                 `json:"title,omitempty"`
+	Msg        string                 `json:"msg,omitempty"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// EventSender is implemented by senders receiving the structured event instead of the rendered message.
+// Events without message template are sent, too.
+type EventSender interface {
+	Sender
+	SendEvent(Message)
+}
+
 var log = util.NewLogger("push")
 
 // NewMessengerFromConfig creates a new messenger
@@ -25,7 +41,17 @@ func NewMessengerFromConfig(typ string, other map[string]interface{}) (res Sende
 	case "telegram":
 		var cc telegramConfig
 		if err = util.DecodeOther(other, &cc); err == nil {
-			res, err = NewTelegramMessenger(cc.Token, cc.Chats)
+			res, err = NewTelegramMessenger(cc.Token, cc.Chats, cc.Commands)
+		}
+	case "webhook":
+		var cc webhookConfig
+		if err = util.DecodeOther(other, &cc); err == nil {
+			res, err = NewWebhookMessenger(cc)
+		}
+	case "mqtt":
+		var cc mqttConfig
+		if err = util.DecodeOther(other, &cc); err == nil {
+			res, err = NewMQTTMessenger(cc.Topic)
 		}
 	case "email", "shout":
 		var cc shoutrrrConfig
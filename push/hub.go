@@ -1,18 +1,22 @@
 package push
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/Masterminds/sprig/v3"
+	"github.com/evcc-io/evcc/core/loadpoint"
 	"github.com/evcc-io/evcc/util"
 )
 
 // Event is a notification event
 type Event struct {
-	LoadPoint *int // optional loadpoint id
-	Event     string
+	LoadPoint  *int // optional loadpoint id
+	Event      string
+	Attributes map[string]interface{} // optional event specific template values
 }
 
 // EventTemplateConfig is the push message configuration for an event
@@ -25,10 +29,25 @@ type EventTemplate struct {
 	Title, Msg *template.Template
 }
 
+// newEventTemplate parses the event's title and message templates
+func newEventTemplate(cc EventTemplateConfig) (EventTemplate, error) {
+	var def EventTemplate
+	var err error
+
+	def.Title, err = template.New("out").Funcs(template.FuncMap(sprig.FuncMap())).Parse(cc.Title)
+	if err == nil {
+		def.Msg, err = template.New("out").Funcs(template.FuncMap(sprig.FuncMap())).Parse(cc.Msg)
+	}
+
+	return def, err
+}
+
 // Hub subscribes to event notifications and sends them to client devices
 type Hub struct {
+	mu          sync.Mutex
 	definitions map[string]EventTemplate
 	sender      []Sender
+	monitor     *Monitor
 	cache       *util.Cache
 }
 
@@ -38,14 +57,7 @@ func NewHub(cc map[string]EventTemplateConfig, cache *util.Cache) (*Hub, error)
 
 	// instantiate all event templates
 	for k, v := range cc {
-		var def EventTemplate
-		var err error
-
-		def.Title, err = template.New("out").Funcs(template.FuncMap(sprig.FuncMap())).Parse(v.Title)
-		if err == nil {
-			def.Msg, err = template.New("out").Funcs(template.FuncMap(sprig.FuncMap())).Parse(v.Msg)
-		}
-
+		def, err := newEventTemplate(v)
 		if err != nil {
 			return nil, err
 		}
@@ -63,11 +75,110 @@ func NewHub(cc map[string]EventTemplateConfig, cache *util.Cache) (*Hub, error)
 
 // Add adds a sender to the list of senders
 func (h *Hub) Add(sender Sender) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	h.sender = append(h.sender, sender)
 }
 
-// apply applies the event template to the content to produce the actual message
-func (h *Hub) apply(ev Event, tmpl *template.Template) (string, error) {
+// SetMonitor sets the monitor creating threshold and report events
+func (h *Hub) SetMonitor(monitor *Monitor) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.monitor = monitor
+}
+
+// Control enables remote control of the loadpoints for all messengers supporting commands
+func (h *Hub) Control(lps []loadpoint.API) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cmd := NewCommander(lps, h.Render)
+
+	for _, sender := range h.sender {
+		if c, ok := sender.(Controller); ok {
+			c.Control(cmd)
+		}
+	}
+}
+
+// Replace replaces definitions, senders and monitor with those of the other hub
+func (h *Hub) Replace(other *Hub) {
+	other.mu.Lock()
+	definitions, sender, monitor := other.definitions, other.sender, other.monitor
+	other.mu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	monitor.Inherit(h.monitor)
+
+	// stop replaced messengers receiving commands
+	h.stop()
+
+	h.definitions = definitions
+	h.sender = sender
+	h.monitor = monitor
+}
+
+// Stop stops all senders receiving commands
+func (h *Hub) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stop()
+}
+
+func (h *Hub) stop() {
+	for _, s := range h.sender {
+		if s, ok := s.(interface{ Stop() }); ok {
+			s.Stop()
+		}
+	}
+}
+
+// Render renders the event using its configured or default template
+func (h *Hub) Render(ev Event) (string, string, error) {
+	h.mu.Lock()
+	definition, ok := h.definitions[ev.Event]
+	h.mu.Unlock()
+
+	if !ok {
+		cc, ok := defaultEvents[ev.Event]
+		if !ok {
+			return "", "", fmt.Errorf("undefined event: %s", ev.Event)
+		}
+
+		var err error
+		if definition, err = newEventTemplate(cc); err != nil {
+			return "", "", err
+		}
+	}
+
+	attr := h.attributes(ev)
+
+	title, err := h.apply(definition.Title, attr)
+	if err == nil {
+		var msg string
+		msg, err = h.apply(definition.Msg, attr)
+		return title, msg, err
+	}
+
+	return "", "", err
+}
+
+// config returns the current definition for the event and the list of senders
+func (h *Hub) config(event string) (EventTemplate, []Sender, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	definition, ok := h.definitions[event]
+	return definition, h.sender, ok
+}
+
+// attributes returns the cached site and loadpoint values and the event's attributes
+func (h *Hub) attributes(ev Event) map[string]interface{} {
 	attr := make(map[string]interface{})
 
 	// let cache catch up, refs reverted https://github.com/evcc-io/evcc/pull/445
@@ -75,11 +186,20 @@ func (h *Hub) apply(ev Event, tmpl *template.Template) (string, error) {
 
 	// get all values from cache
 	for _, p := range h.cache.All() {
-		if p.LoadPoint == nil || ev.LoadPoint == p.LoadPoint {
+		if p.LoadPoint == nil || ev.LoadPoint != nil && *ev.LoadPoint == *p.LoadPoint {
 			attr[p.Key] = p.Val
 		}
 	}
 
+	for k, v := range ev.Attributes {
+		attr[k] = v
+	}
+
+	return attr
+}
+
+// apply applies the event template to the content to produce the actual message
+func (h *Hub) apply(tmpl *template.Template, attr map[string]interface{}) (string, error) {
 	// apply data attributes to template using sprig functions
 	applied := new(strings.Builder)
 	if err := tmpl.Execute(applied, attr); err != nil {
@@ -89,36 +209,76 @@ func (h *Hub) apply(ev Event, tmpl *template.Template) (string, error) {
 	return util.ReplaceFormatted(applied.String(), attr)
 }
 
+// hasEventSender returns true if any sender receives structured events
+func hasEventSender(senders []Sender) bool {
+	for _, sender := range senders {
+		if _, ok := sender.(EventSender); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Run is the Hub's main publishing loop
 func (h *Hub) Run(events <-chan Event) {
 	for ev := range events {
-		if len(h.sender) == 0 {
+		definition, senders, ok := h.config(ev.Event)
+
+		// structured events are sent without message template
+		if len(senders) == 0 || !ok && !hasEventSender(senders) {
 			continue
 		}
 
-		definition, ok := h.definitions[ev.Event]
-		if !ok {
-			continue
+		attr := h.attributes(ev)
+
+		var title, msg string
+		if ok {
+			var err error
+			if title, err = h.apply(definition.Title, attr); err != nil {
+				log.ERROR.Printf("invalid title template for %s: %v", ev.Event, err)
+				continue
+			}
+
+			if msg, err = h.apply(definition.Msg, attr); err != nil {
+				log.ERROR.Printf("invalid message template for %s: %v", ev.Event, err)
+				continue
+			}
 		}
 
-		title, err := h.apply(ev, definition.Title)
-		if err != nil {
-			log.ERROR.Printf("invalid title template for %s: %v", ev.Event, err)
-			continue
+		m := Message{
+			Event:      ev.Event,
+			Title:      title,
+			Msg:        msg,
+			Attributes: attr,
 		}
 
-		msg, err := h.apply(ev, definition.Msg)
-		if err != nil {
-			log.ERROR.Printf("invalid message template for %s: %v", ev.Event, err)
-			continue
+		// loadpoint number as used by api and templates
+		if ev.LoadPoint != nil {
+			lp := *ev.LoadPoint + 1
+			m.LoadPoint = &lp
 		}
 
-		for _, sender := range h.sender {
-			if strings.TrimSpace(msg) != "" {
+		for _, sender := range senders {
+			if s, isEvent := sender.(EventSender); isEvent {
+				go s.SendEvent(m)
+			} else if strings.TrimSpace(msg) != "" {
 				go sender.Send(title, msg)
-			} else {
-				log.DEBUG.Printf("did not send empty message template for %s: %v", ev.Event, err)
+			} else if ok {
+				log.DEBUG.Printf("did not send empty message template for %s", ev.Event)
 			}
 		}
 	}
 }
+
+// Watch sends the monitor's events to the channel in the given interval
+func (h *Hub) Watch(events chan<- Event, interval time.Duration) {
+	for now := range time.Tick(interval) {
+		h.mu.Lock()
+		monitor := h.monitor
+		h.mu.Unlock()
+
+		for _, ev := range monitor.Evaluate(now) {
+			events <- ev
+		}
+	}
+}
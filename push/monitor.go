@@ -0,0 +1,308 @@
+package push
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+)
+
+// report events
+const (
+	DailyReport  = "dailyReport"
+	WeeklyReport = "weeklyReport"
+)
+
+// reportKeys are the cumulative site values summarized by reports
+var reportKeys = map[string]string{
+	"savingsTotalCharged":           "reportCharged",
+	"savingsGridCharged":            "reportGridCharged",
+	"savingsSelfConsumptionCharged": "reportSelfConsumptionCharged",
+	"savingsAmount":                 "reportSavingsAmount",
+	"savingsAvoidedCO2":             "reportAvoidedCO2",
+}
+
+// ThresholdConfig defines a custom event fired when a cached value crosses the threshold
+type ThresholdConfig struct {
+	Event      string
+	Key        string
+	LoadPoint  int    // loadpoint number starting at 1, site value if empty
+	Comparison string // <, <=, >, >=
+	Value      float64
+	Hysteresis float64 // distance from the threshold required before the event fires again
+}
+
+// ReportConfig defines the schedule of daily and weekly report events
+type ReportConfig struct {
+	Time    string // time of day as hh:mm, reports are disabled if empty
+	Weekday string // day of the weekly report, defaults to sunday
+}
+
+type threshold struct {
+	ThresholdConfig
+	fired bool
+}
+
+// crossed returns true if the value satisfies the comparison
+func (t *threshold) crossed(val float64) bool {
+	switch t.Comparison {
+	case "<":
+		return val < t.Value
+	case "<=":
+		return val <= t.Value
+	case ">":
+		return val > t.Value
+	default:
+		return val >= t.Value
+	}
+}
+
+// rearmed returns true if the value has left the threshold by at least the hysteresis
+func (t *threshold) rearmed(val float64) bool {
+	if strings.HasPrefix(t.Comparison, "<") {
+		return val >= t.Value+t.Hysteresis
+	}
+	return val <= t.Value-t.Hysteresis
+}
+
+type report struct {
+	event    string
+	next     time.Time
+	since    time.Time
+	snapshot map[string]float64
+}
+
+// Monitor creates threshold and report events from cached values
+type Monitor struct {
+	mu         sync.Mutex
+	cache      *util.Cache
+	thresholds []*threshold
+	config     ReportConfig
+	reports    []*report
+}
+
+// NewMonitor creates a monitor for the given thresholds and report schedule
+func NewMonitor(thresholds []ThresholdConfig, cc ReportConfig, cache *util.Cache) (*Monitor, error) {
+	m := &Monitor{
+		cache:  cache,
+		config: cc,
+	}
+
+	for i, t := range thresholds {
+		if t.Event == "" || t.Key == "" {
+			return nil, fmt.Errorf("threshold %d: missing event or key", i)
+		}
+
+		switch t.Comparison {
+		case "<", "<=", ">", ">=":
+		case "":
+			return nil, fmt.Errorf("threshold %d: missing comparison", i)
+		default:
+			return nil, fmt.Errorf("threshold %d: invalid comparison: %s", i, t.Comparison)
+		}
+
+		if t.Hysteresis < 0 || t.LoadPoint < 0 {
+			return nil, fmt.Errorf("threshold %d: invalid hysteresis or loadpoint", i)
+		}
+
+		m.thresholds = append(m.thresholds, &threshold{ThresholdConfig: t})
+	}
+
+	if cc.Time == "" {
+		return m, nil
+	}
+
+	if _, err := time.Parse("15:04", cc.Time); err != nil {
+		return nil, fmt.Errorf("invalid report time: %s", cc.Time)
+	}
+
+	if _, err := weekday(cc.Weekday); err != nil {
+		return nil, err
+	}
+
+	m.reports = []*report{{event: DailyReport}, {event: WeeklyReport}}
+
+	return m, nil
+}
+
+// weekday parses the weekday name
+func weekday(s string) (time.Weekday, error) {
+	if s == "" {
+		return time.Sunday, nil
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) || strings.EqualFold(s, d.String()[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid report weekday: %s", s)
+}
+
+// next returns the next schedule of the report after now
+func (m *Monitor) next(r *report, now time.Time) time.Time {
+	hm, _ := time.Parse("15:04", m.config.Time)
+	ts := time.Date(now.Year(), now.Month(), now.Day(), hm.Hour(), hm.Minute(), 0, 0, now.Location())
+
+	if r.event == WeeklyReport {
+		day, _ := weekday(m.config.Weekday)
+		ts = ts.AddDate(0, 0, (int(day)-int(ts.Weekday())+7)%7)
+	}
+
+	if !ts.After(now) {
+		if r.event == WeeklyReport {
+			ts = ts.AddDate(0, 0, 7)
+		} else {
+			ts = ts.AddDate(0, 0, 1)
+		}
+	}
+
+	return ts
+}
+
+// Inherit takes over the report state of the previous monitor if the schedule is unchanged
+func (m *Monitor) Inherit(prev *Monitor) {
+	if m == nil || prev == nil {
+		return
+	}
+
+	prev.mu.Lock()
+	defer prev.mu.Unlock()
+
+	if m.config == prev.config {
+		m.mu.Lock()
+		m.reports = prev.reports
+		m.mu.Unlock()
+	}
+}
+
+// value returns the numeric cache value
+func (m *Monitor) value(key string, lp int) (float64, bool) {
+	p := util.Param{Key: key}
+	if lp > 0 {
+		id := lp - 1
+		p.LoadPoint = &id
+	}
+
+	switch v := m.cache.Get(p.UniqueID()).Val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// Evaluate returns the events triggered at the given time
+func (m *Monitor) Evaluate(now time.Time) []Event {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []Event
+
+	for _, t := range m.thresholds {
+		val, ok := m.value(t.Key, t.LoadPoint)
+		if !ok {
+			continue
+		}
+
+		if t.fired {
+			t.fired = !t.rearmed(val)
+			continue
+		}
+
+		if t.crossed(val) {
+			t.fired = true
+
+			ev := Event{
+				Event: t.Event,
+				Attributes: map[string]interface{}{
+					"thresholdKey":   t.Key,
+					"thresholdValue": t.Value,
+					"value":          val,
+				},
+			}
+
+			if t.LoadPoint > 0 {
+				id := t.LoadPoint - 1
+				ev.LoadPoint = &id
+			}
+
+			res = append(res, ev)
+		}
+	}
+
+	for _, r := range m.reports {
+		if ev, ok := m.report(r, now); ok {
+			res = append(res, ev)
+		}
+	}
+
+	return res
+}
+
+// report returns the report event if it is due. The first evaluation with available values only records them.
+func (m *Monitor) report(r *report, now time.Time) (Event, bool) {
+	current := make(map[string]float64)
+	for key := range reportKeys {
+		if val, ok := m.value(key, 0); ok {
+			current[key] = val
+		}
+	}
+
+	if len(current) == 0 {
+		return Event{}, false
+	}
+
+	if r.snapshot == nil {
+		r.next = m.next(r, now)
+		r.since = now
+		r.snapshot = current
+		return Event{}, false
+	}
+
+	if now.Before(r.next) {
+		return Event{}, false
+	}
+
+	attr := map[string]interface{}{
+		"reportSince": r.since,
+	}
+
+	for key, attrKey := range reportKeys {
+		// counters may have been reset
+		delta := current[key] - r.snapshot[key]
+		if delta < 0 {
+			delta = current[key]
+		}
+		attr[attrKey] = delta
+	}
+
+	// self consumption share of the charged energy
+	var percent float64
+	if charged := attr["reportCharged"].(float64); charged > 0 {
+		percent = 100 * attr["reportSelfConsumptionCharged"].(float64) / charged
+	}
+	attr["reportSelfConsumptionPercent"] = percent
+
+	r.next = m.next(r, now)
+	r.since = now
+	r.snapshot = current
+
+	return Event{Event: r.event, Attributes: attr}, true
+}
@@ -0,0 +1,148 @@
+package push
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+)
+
+func TestMonitorThreshold(t *testing.T) {
+	cache := util.NewCache()
+
+	m, err := NewMonitor([]ThresholdConfig{{
+		Event:      "batteryLow",
+		Key:        "batterySoC",
+		Comparison: "<",
+		Value:      20,
+		Hysteresis: 5,
+	}}, ReportConfig{}, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		soc   float64
+		fired bool
+	}{
+		{50, false},
+		{19, true},
+		{15, false}, // already fired
+		{22, false}, // within hysteresis
+		{18, false},
+		{25, false}, // re-armed
+		{19, true},
+	} {
+		cache.Add("batterySoC", util.Param{Key: "batterySoC", Val: tc.soc})
+
+		res := m.Evaluate(time.Now())
+		if tc.fired != (len(res) == 1) {
+			t.Fatalf("soc %.0f: unexpected events %v", tc.soc, res)
+		}
+
+		if tc.fired && (res[0].Event != "batteryLow" || res[0].Attributes["value"] != tc.soc || res[0].LoadPoint != nil) {
+			t.Errorf("soc %.0f: unexpected event %+v", tc.soc, res[0])
+		}
+	}
+}
+
+func TestMonitorLoadpointThreshold(t *testing.T) {
+	cache := util.NewCache()
+
+	m, err := NewMonitor([]ThresholdConfig{{
+		Event:      "chargePowerHigh",
+		Key:        "chargePower",
+		LoadPoint:  2,
+		Comparison: ">=",
+		Value:      10000,
+	}}, ReportConfig{}, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := 0
+	p := util.Param{LoadPoint: &id, Key: "chargePower", Val: 11000.0}
+	cache.Add(p.UniqueID(), p)
+
+	if res := m.Evaluate(time.Now()); len(res) != 0 {
+		t.Errorf("unexpected events for other loadpoint: %v", res)
+	}
+
+	id2 := 1
+	p = util.Param{LoadPoint: &id2, Key: "chargePower", Val: 11000.0}
+	cache.Add(p.UniqueID(), p)
+
+	res := m.Evaluate(time.Now())
+	if len(res) != 1 || res[0].LoadPoint == nil || *res[0].LoadPoint != 1 {
+		t.Errorf("unexpected events: %v", res)
+	}
+}
+
+func TestMonitorConfig(t *testing.T) {
+	for _, tc := range []struct {
+		thresholds []ThresholdConfig
+		report     ReportConfig
+	}{
+		{[]ThresholdConfig{{Key: "batterySoC", Comparison: "<"}}, ReportConfig{}},
+		{[]ThresholdConfig{{Event: "foo", Key: "batterySoC"}}, ReportConfig{}},
+		{[]ThresholdConfig{{Event: "foo", Key: "batterySoC", Comparison: "=="}}, ReportConfig{}},
+		{nil, ReportConfig{Time: "25:00"}},
+		{nil, ReportConfig{Time: "21:00", Weekday: "someday"}},
+	} {
+		if _, err := NewMonitor(tc.thresholds, tc.report, nil); err == nil {
+			t.Errorf("%+v %+v: expected error", tc.thresholds, tc.report)
+		}
+	}
+}
+
+func TestMonitorReport(t *testing.T) {
+	cache := util.NewCache()
+
+	m, err := NewMonitor(nil, ReportConfig{Time: "21:00", Weekday: "sat"}, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := func(charged, self float64) {
+		cache.Add("savingsTotalCharged", util.Param{Key: "savingsTotalCharged", Val: charged})
+		cache.Add("savingsSelfConsumptionCharged", util.Param{Key: "savingsSelfConsumptionCharged", Val: self})
+	}
+
+	// friday
+	now := time.Date(2021, 11, 19, 12, 0, 0, 0, time.Local)
+
+	// no values yet
+	if res := m.Evaluate(now); len(res) != 0 {
+		t.Fatalf("unexpected events: %v", res)
+	}
+
+	set(100, 50)
+	if res := m.Evaluate(now); len(res) != 0 {
+		t.Fatalf("unexpected events: %v", res)
+	}
+
+	set(110, 58)
+	if res := m.Evaluate(now.Add(8*time.Hour + 59*time.Minute)); len(res) != 0 {
+		t.Fatalf("unexpected events: %v", res)
+	}
+
+	res := m.Evaluate(now.Add(9 * time.Hour))
+	if len(res) != 1 || res[0].Event != DailyReport {
+		t.Fatalf("expected daily report, got %v", res)
+	}
+
+	if attr := res[0].Attributes; attr["reportCharged"] != 10.0 || attr["reportSelfConsumptionPercent"] != 80.0 || attr["reportSince"] != now {
+		t.Errorf("unexpected report: %v", attr)
+	}
+
+	// saturday
+	set(120, 60)
+	res = m.Evaluate(now.Add(33 * time.Hour))
+	if len(res) != 2 || res[0].Event != DailyReport || res[1].Event != WeeklyReport {
+		t.Fatalf("expected daily and weekly report, got %v", res)
+	}
+
+	if daily, weekly := res[0].Attributes["reportCharged"], res[1].Attributes["reportCharged"]; daily != 10.0 || weekly != 20.0 {
+		t.Errorf("unexpected totals: %v %v", daily, weekly)
+	}
+}
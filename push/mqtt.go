@@ -0,0 +1,57 @@
+package push
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/evcc-io/evcc/provider/mqtt"
+)
+
+// MQTT implements the mqtt messenger publishing structured events
+type MQTT struct {
+	client *mqtt.Client
+	topic  string
+}
+
+type mqttConfig struct {
+	Topic string
+}
+
+var _ EventSender = (*MQTT)(nil)
+
+// NewMQTTMessenger creates new mqtt messenger using the global mqtt connection
+func NewMQTTMessenger(topic string) (*MQTT, error) {
+	if mqtt.Instance == nil {
+		return nil, errors.New("mqtt: missing mqtt broker configuration")
+	}
+
+	if topic == "" {
+		return nil, errors.New("mqtt: missing topic")
+	}
+
+	m := &MQTT{
+		client: mqtt.Instance,
+		topic:  topic,
+	}
+
+	return m, nil
+}
+
+// Send implements the Sender interface
+func (m *MQTT) Send(title, msg string) {
+	m.SendEvent(Message{Title: title, Msg: msg})
+}
+
+// SendEvent publishes the event as json
+func (m *MQTT) SendEvent(msg Message) {
+	msg.Attributes = jsonAttributes(msg.Attributes)
+
+	b, err := json.Marshal(msg)
+	if err == nil {
+		err = m.client.Publish(m.topic, false, b)
+	}
+
+	if err != nil {
+		log.ERROR.Printf("mqtt: %v", err)
+	}
+}
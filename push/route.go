@@ -0,0 +1,235 @@
+package push
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// DigestEvent is the event of messages queued during quiet hours
+const DigestEvent = "digest"
+
+// RouteConfig selects the events delivered to a sender. Empty lists match everything.
+type RouteConfig struct {
+	Events     []string
+	LoadPoints []int    // loadpoint numbers starting at 1
+	Vehicles   []string // vehicle titles or identifiers
+}
+
+// QuietConfig defines the quiet hours of a sender. Events not listed as critical are queued and delivered as digest afterwards.
+type QuietConfig struct {
+	From, To string // time of day as hh:mm
+	Critical []string
+}
+
+// Router delivers the events matching its route to the sender, respecting quiet hours
+type Router struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	sender   Sender
+	route    RouteConfig
+	quiet    QuietConfig
+	from, to int // quiet hours as minutes of day
+	queue    []Message
+	timer    *clock.Timer
+}
+
+var _ EventSender = (*Router)(nil)
+
+// minutes parses the hh:mm time of day
+func minutes(s string) (int, error) {
+	ts, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time: %s", s)
+	}
+	return 60*ts.Hour() + ts.Minute(), nil
+}
+
+// NewRouter creates a router for the sender. The sender is returned unchanged if neither route nor quiet hours are defined.
+func NewRouter(sender Sender, route RouteConfig, quiet QuietConfig) (Sender, error) {
+	r := &Router{
+		clock:  clock.New(),
+		sender: sender,
+		route:  route,
+		quiet:  quiet,
+	}
+
+	if quiet.From != "" || quiet.To != "" {
+		var err error
+		if r.from, err = minutes(quiet.From); err == nil {
+			r.to, err = minutes(quiet.To)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("quiet hours: %w", err)
+		}
+
+		if r.from == r.to {
+			return nil, fmt.Errorf("quiet hours: empty period %s-%s", quiet.From, quiet.To)
+		}
+	} else if len(route.Events)+len(route.LoadPoints)+len(route.Vehicles) == 0 {
+		return sender, nil
+	}
+
+	return r, nil
+}
+
+// contains returns true if the list contains the string ignoring case
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the message is selected by the route
+func (r *Router) matches(m Message) bool {
+	if len(r.route.Events) > 0 && !contains(r.route.Events, m.Event) {
+		return false
+	}
+
+	if len(r.route.LoadPoints) > 0 {
+		if m.LoadPoint == nil {
+			return false
+		}
+
+		var ok bool
+		for _, lp := range r.route.LoadPoints {
+			ok = ok || lp == *m.LoadPoint
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	if len(r.route.Vehicles) > 0 {
+		title, _ := m.Attributes["vehicleTitle"].(string)
+		id, _ := m.Attributes["vehicleIdentity"].(string)
+
+		if !(title != "" && contains(r.route.Vehicles, title) || id != "" && contains(r.route.Vehicles, id)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// quietUntil returns the end of the quiet hours if now is within
+func (r *Router) quietUntil(now time.Time) (time.Time, bool) {
+	if r.quiet.From == "" {
+		return time.Time{}, false
+	}
+
+	m := 60*now.Hour() + now.Minute()
+
+	var quiet bool
+	if r.from < r.to {
+		quiet = m >= r.from && m < r.to
+	} else {
+		quiet = m >= r.from || m < r.to
+	}
+
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	end := time.Date(now.Year(), now.Month(), now.Day(), r.to/60, r.to%60, 0, 0, now.Location())
+	if !end.After(now) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return end, true
+}
+
+// Send implements the Sender interface
+func (r *Router) Send(title, msg string) {
+	r.SendEvent(Message{Title: title, Msg: msg})
+}
+
+// SendEvent delivers or queues the event if it matches the route
+func (r *Router) SendEvent(m Message) {
+	if _, ok := r.sender.(EventSender); !ok && strings.TrimSpace(m.Msg) == "" || !r.matches(m) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if end, ok := r.quietUntil(r.clock.Now()); ok && !contains(r.quiet.Critical, m.Event) {
+		r.queue = append(r.queue, m)
+
+		if r.timer == nil {
+			r.timer = r.clock.AfterFunc(end.Sub(r.clock.Now()), r.digest)
+		}
+
+		return
+	}
+
+	r.deliver(m)
+}
+
+// deliver sends the message to the sender
+func (r *Router) deliver(m Message) {
+	if s, ok := r.sender.(EventSender); ok {
+		s.SendEvent(m)
+	} else if strings.TrimSpace(m.Msg) != "" {
+		r.sender.Send(m.Title, m.Msg)
+	}
+}
+
+// digest delivers the queued messages as single message
+func (r *Router) digest() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queue := r.queue
+	r.queue = nil
+	r.timer = nil
+
+	if len(queue) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, m := range queue {
+		if msg := strings.TrimSpace(m.Msg); msg != "" {
+			if m.Title != "" {
+				msg = m.Title + ": " + msg
+			}
+			lines = append(lines, msg)
+		}
+	}
+
+	events := make([]Message, 0, len(queue))
+	for _, m := range queue {
+		m.Attributes = jsonAttributes(m.Attributes)
+		events = append(events, m)
+	}
+
+	r.deliver(Message{
+		Event:      DigestEvent,
+		Title:      fmt.Sprintf("%d messages during quiet hours", len(events)),
+		Msg:        strings.Join(lines, "\n"),
+		Attributes: map[string]interface{}{"events": events},
+	})
+}
+
+// Control implements the Controller interface
+func (r *Router) Control(cmd *Commander) {
+	if c, ok := r.sender.(Controller); ok {
+		c.Control(cmd)
+	}
+}
+
+// Stop stops the sender's command processing
+func (r *Router) Stop() {
+	if s, ok := r.sender.(interface{ Stop() }); ok {
+		s.Stop()
+	}
+}
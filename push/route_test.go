@@ -0,0 +1,113 @@
+package push
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+type textSender struct {
+	titles, msgs []string
+}
+
+func (s *textSender) Send(title, msg string) {
+	s.titles = append(s.titles, title)
+	s.msgs = append(s.msgs, msg)
+}
+
+func TestRouterPassthrough(t *testing.T) {
+	sender := new(textSender)
+
+	if r, err := NewRouter(sender, RouteConfig{}, QuietConfig{}); err != nil || r != sender {
+		t.Errorf("expected unchanged sender, got %v %v", r, err)
+	}
+
+	for _, quiet := range []QuietConfig{{From: "22:00"}, {From: "22:00", To: "22:00"}, {From: "25:00", To: "07:00"}} {
+		if _, err := NewRouter(sender, RouteConfig{}, quiet); err == nil {
+			t.Errorf("%+v: expected error", quiet)
+		}
+	}
+}
+
+func TestRouterRoute(t *testing.T) {
+	sender := new(textSender)
+
+	r, err := NewRouter(sender, RouteConfig{
+		Events:     []string{"start", "stop"},
+		LoadPoints: []int{2},
+		Vehicles:   []string{"model 3", "WVWZZZ"},
+	}, QuietConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp1, lp2 := 1, 2
+
+	for _, tc := range []struct {
+		m    Message
+		sent bool
+	}{
+		{Message{Event: "start", LoadPoint: &lp2, Msg: "empty vehicle"}, false},
+		{Message{Event: "start", LoadPoint: &lp2, Msg: "title", Attributes: map[string]interface{}{"vehicleTitle": "Model 3"}}, true},
+		{Message{Event: "start", LoadPoint: &lp2, Msg: "identity", Attributes: map[string]interface{}{"vehicleIdentity": "WVWZZZ"}}, true},
+		{Message{Event: "start", LoadPoint: &lp2, Msg: "other vehicle", Attributes: map[string]interface{}{"vehicleTitle": "e-Golf"}}, false},
+		{Message{Event: "start", LoadPoint: &lp1, Msg: "other loadpoint", Attributes: map[string]interface{}{"vehicleTitle": "Model 3"}}, false},
+		{Message{Event: "start", Msg: "site", Attributes: map[string]interface{}{"vehicleTitle": "Model 3"}}, false},
+		{Message{Event: "soc", LoadPoint: &lp2, Msg: "other event", Attributes: map[string]interface{}{"vehicleTitle": "Model 3"}}, false},
+		{Message{Event: "stop", LoadPoint: &lp2, Attributes: map[string]interface{}{"vehicleTitle": "Model 3"}}, false}, // no message
+	} {
+		sender.msgs = nil
+		r.(EventSender).SendEvent(tc.m)
+
+		if sent := len(sender.msgs) == 1; sent != tc.sent {
+			t.Errorf("%s: expected sent %v", tc.m.Msg, tc.sent)
+		}
+	}
+}
+
+func TestRouterQuietHours(t *testing.T) {
+	sender := new(textSender)
+
+	s, err := NewRouter(sender, RouteConfig{}, QuietConfig{From: "22:00", To: "07:00", Critical: []string{"deviceFailure"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clck := clock.NewMock()
+	clck.Set(time.Date(2021, 11, 19, 21, 0, 0, 0, time.UTC))
+
+	r := s.(*Router)
+	r.clock = clck
+
+	r.SendEvent(Message{Event: "stop", Title: "Stop", Msg: "before"})
+	if len(sender.msgs) != 1 {
+		t.Fatalf("unexpected messages: %v", sender.msgs)
+	}
+
+	clck.Add(2 * time.Hour) // 23:00
+	r.SendEvent(Message{Event: "stop", Title: "Stop", Msg: "night"})
+
+	clck.Add(4 * time.Hour) // 03:00
+	r.SendEvent(Message{Event: "start", Title: "Start", Msg: "early"})
+	r.SendEvent(Message{Event: "deviceFailure", Title: "Failure", Msg: "critical"})
+
+	if len(sender.msgs) != 2 || sender.msgs[1] != "critical" {
+		t.Fatalf("unexpected messages: %v", sender.msgs)
+	}
+
+	clck.Add(4 * time.Hour) // 07:00
+	if len(sender.msgs) != 3 {
+		t.Fatalf("expected digest, got %v", sender.msgs)
+	}
+
+	if title, msg := sender.titles[2], sender.msgs[2]; title != "2 messages during quiet hours" || !strings.Contains(msg, "Stop: night\nStart: early") {
+		t.Errorf("unexpected digest: %s %s", title, msg)
+	}
+
+	r.SendEvent(Message{Event: "start", Title: "Start", Msg: "after"})
+	if len(sender.msgs) != 4 {
+		t.Errorf("unexpected messages: %v", sender.msgs)
+	}
+}
@@ -10,13 +10,16 @@ import (
 // Telegram implements the Telegram messenger
 type Telegram struct {
 	sync.Mutex
-	bot   *tgbotapi.BotAPI
-	chats map[int64]struct{}
+	bot      *tgbotapi.BotAPI
+	chats    map[int64]struct{}
+	commands bool
+	cmd      *Commander
 }
 
 type telegramConfig struct {
-	Token string
-	Chats []int64
+	Token    string
+	Chats    []int64
+	Commands bool // accept remote control commands from chats
 }
 
 func init() {
@@ -26,15 +29,16 @@ func init() {
 }
 
 // NewTelegramMessenger creates new pushover messenger
-func NewTelegramMessenger(token string, chats []int64) (*Telegram, error) {
+func NewTelegramMessenger(token string, chats []int64, commands bool) (*Telegram, error) {
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, errors.New("telegram: invalid bot token")
 	}
 
 	m := &Telegram{
-		bot:   bot,
-		chats: make(map[int64]struct{}),
+		bot:      bot,
+		chats:    make(map[int64]struct{}),
+		commands: commands,
 	}
 
 	for _, chat := range chats {
@@ -46,18 +50,94 @@ func NewTelegramMessenger(token string, chats []int64) (*Telegram, error) {
 	return m, nil
 }
 
-// trackChats captures ids of all chats that bot participates in
+// Control implements the Controller interface
+func (m *Telegram) Control(cmd *Commander) {
+	if !m.commands {
+		return
+	}
+
+	m.Lock()
+	m.cmd = cmd
+	m.Unlock()
+}
+
+// Stop stops receiving updates
+func (m *Telegram) Stop() {
+	m.bot.StopReceivingUpdates()
+}
+
+// allowed returns the commander if commands from the chat are accepted
+func (m *Telegram) allowed(chat int64) (*Commander, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	_, ok := m.chats[chat]
+	return m.cmd, ok && m.cmd != nil
+}
+
+// trackChats captures ids of all chats that bot participates in and handles commands of known chats
 func (m *Telegram) trackChats() {
 	conf := tgbotapi.NewUpdate(0)
 	conf.Timeout = 1000
 
 	for update := range m.bot.GetUpdatesChan(conf) {
-		m.Lock()
-		if _, ok := m.chats[update.Message.Chat.ID]; !ok {
-			log.INFO.Printf("telegram: new chat id: %d", update.Message.Chat.ID)
-			// m.chats[update.Message.Chat.ID] = struct{}{}
+		if cb := update.CallbackQuery; cb != nil && cb.Message != nil {
+			// acknowledge button press
+			if _, err := m.bot.Request(tgbotapi.NewCallback(cb.ID, "")); err != nil {
+				log.ERROR.Printf("telegram: %v", err)
+			}
+
+			m.execute(cb.Message.Chat.ID, cb.Data)
+			continue
+		}
+
+		if update.Message == nil {
+			continue
+		}
+
+		chat := update.Message.Chat.ID
+		if _, ok := m.allowed(chat); !ok {
+			m.Lock()
+			if _, ok := m.chats[chat]; !ok {
+				log.INFO.Printf("telegram: new chat id: %d", chat)
+			}
+			m.Unlock()
+			continue
+		}
+
+		if update.Message.IsCommand() {
+			m.execute(chat, update.Message.Text)
+		}
+	}
+}
+
+// execute executes the command if the chat is allowed and sends the reply
+func (m *Telegram) execute(chat int64, text string) {
+	cmd, ok := m.allowed(chat)
+	if !ok {
+		log.WARN.Printf("telegram: ignoring command from unknown chat id: %d", chat)
+		return
+	}
+
+	log.DEBUG.Printf("telegram: command from %d: %s", chat, text)
+
+	res, err := cmd.Execute(text)
+	if err != nil {
+		res = Reply{Text: err.Error()}
+	}
+
+	msg := tgbotapi.NewMessage(chat, res.Text)
+
+	if len(res.Choices) > 0 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, c := range res.Choices {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(c.Label, c.Command))
 		}
-		m.Unlock()
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	}
+
+	if _, err := m.bot.Send(msg); err != nil {
+		log.ERROR.Print(err)
 	}
 }
 
@@ -0,0 +1,122 @@
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/avast/retry-go/v3"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/request"
+)
+
+// Webhook implements the webhook messenger posting structured events
+type Webhook struct {
+	*request.Helper
+	uri     string
+	method  string
+	headers map[string]string
+	retries uint
+	delay   time.Duration
+}
+
+type webhookConfig struct {
+	URI     string
+	Method  string
+	Headers map[string]string
+	Retries uint
+	Delay   time.Duration
+	Timeout time.Duration
+}
+
+var _ EventSender = (*Webhook)(nil)
+
+// NewWebhookMessenger creates new webhook messenger
+func NewWebhookMessenger(cc webhookConfig) (*Webhook, error) {
+	if cc.URI == "" {
+		return nil, errors.New("webhook: missing uri")
+	}
+
+	if cc.Method == "" {
+		cc.Method = http.MethodPost
+	}
+
+	if cc.Retries == 0 {
+		cc.Retries = 3
+	}
+
+	if cc.Delay == 0 {
+		cc.Delay = 5 * time.Second
+	}
+
+	headers := map[string]string{
+		"Content-Type": request.JSONContent,
+	}
+	for k, v := range cc.Headers {
+		headers[k] = v
+	}
+
+	m := &Webhook{
+		Helper:  request.NewHelper(util.NewLogger("webhook")),
+		uri:     cc.URI,
+		method:  strings.ToUpper(cc.Method),
+		headers: headers,
+		retries: cc.Retries,
+		delay:   cc.Delay,
+	}
+
+	if cc.Timeout > 0 {
+		m.Client.Timeout = cc.Timeout
+	}
+
+	return m, nil
+}
+
+// jsonAttributes returns the attributes that can be encoded as json
+func jsonAttributes(attr map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(attr))
+
+	for k, v := range attr {
+		if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			continue
+		}
+
+		if _, err := json.Marshal(v); err == nil {
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
+// Send implements the Sender interface
+func (m *Webhook) Send(title, msg string) {
+	m.SendEvent(Message{Title: title, Msg: msg})
+}
+
+// SendEvent posts the event as json
+func (m *Webhook) SendEvent(msg Message) {
+	msg.Attributes = jsonAttributes(msg.Attributes)
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		log.ERROR.Printf("webhook: %v", err)
+		return
+	}
+
+	err = retry.Do(func() error {
+		req, err := request.New(m.method, m.uri, bytes.NewReader(b), m.headers)
+		if err == nil {
+			_, err = m.DoBody(req)
+		}
+		return err
+	}, retry.Attempts(m.retries), retry.Delay(m.delay), retry.DelayType(retry.FixedDelay), retry.LastErrorOnly(true))
+
+	if err != nil {
+		log.ERROR.Printf("webhook: %v", err)
+	}
+}
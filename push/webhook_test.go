@@ -0,0 +1,120 @@
+package push
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/util"
+)
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	var res Message
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// fail first request
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %v", r.Method, r.Header)
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	m, err := NewWebhookMessenger(webhookConfig{
+		URI:     srv.URL,
+		Method:  "put",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Delay:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := 1
+	m.SendEvent(Message{
+		Event:     "start",
+		LoadPoint: &lp,
+		Title:     "Charge started",
+		Attributes: map[string]interface{}{
+			"mode":   "pv",
+			"nan":    math.NaN(),
+			"broken": func() {},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if calls != 2 {
+		t.Errorf("expected retry, got %d calls", calls)
+	}
+
+	if res.Event != "start" || res.LoadPoint == nil || *res.LoadPoint != 1 || res.Title != "Charge started" {
+		t.Errorf("unexpected message: %+v", res)
+	}
+
+	if len(res.Attributes) != 1 || res.Attributes["mode"] != "pv" {
+		t.Errorf("unexpected attributes: %v", res.Attributes)
+	}
+}
+
+type eventSender struct {
+	events chan Message
+}
+
+func (s *eventSender) Send(title, msg string) {}
+
+func (s *eventSender) SendEvent(m Message) {
+	s.events <- m
+}
+
+func TestHubEventSender(t *testing.T) {
+	cache := util.NewCache()
+
+	id := 0
+	p := util.Param{LoadPoint: &id, Key: "mode", Val: "pv"}
+	cache.Add(p.UniqueID(), p)
+
+	hub, err := NewHub(map[string]EventTemplateConfig{
+		"start": {Title: "Start", Msg: "Charging in ${mode} mode"},
+	}, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &eventSender{events: make(chan Message, 1)}
+	hub.Add(sender)
+
+	events := make(chan Event)
+	go hub.Run(events)
+
+	events <- Event{Event: "start", LoadPoint: &id}
+	if m := <-sender.events; m.Event != "start" || *m.LoadPoint != 1 || m.Msg != "Charging in pv mode" || m.Attributes["mode"] != "pv" {
+		t.Errorf("unexpected message: %+v", m)
+	}
+
+	// events without template
+	events <- Event{Event: "stop", Attributes: map[string]interface{}{"foo": "bar"}}
+	if m := <-sender.events; m.Event != "stop" || m.LoadPoint != nil || m.Msg != "" || m.Attributes["foo"] != "bar" {
+		t.Errorf("unexpected message: %+v", m)
+	}
+
+	close(events)
+}
@@ -42,9 +42,14 @@ type HTTPd struct {
 
 // NewHTTPd creates HTTP server with configured routes for loadpoint
 func NewHTTPd(url string, site site.API, hub *SocketHub, cache *util.Cache) *HTTPd {
+	detector := newDetector()
+
 	routes := map[string]route{
-		"health": {[]string{"GET"}, "/health", healthHandler(site)},
-		"state":  {[]string{"GET"}, "/state", stateHandler(cache)},
+		"health":       {[]string{"GET"}, "/health", healthHandler(site)},
+		"state":        {[]string{"GET"}, "/state", stateHandler(cache)},
+		"devices":      {[]string{"GET"}, "/devices", devicesHandler()},
+		"detect":       {[]string{"POST", "OPTIONS"}, "/detect", detector.startHandler},
+		"detectresult": {[]string{"GET"}, "/detect", detector.resultHandler},
 	}
 
 	router := mux.NewRouter().StrictSlash(true)
@@ -52,6 +57,9 @@ func NewHTTPd(url string, site site.API, hub *SocketHub, cache *util.Cache) *HTT
 	// websocket
 	router.HandleFunc("/ws", socketHandler(hub))
 
+	// server-sent events, registered before api to bypass json and compression middleware
+	router.HandleFunc("/api/events", eventsHandler(hub))
+
 	// static - individual handlers per root and folders
 	static := router.PathPrefix("/").Subrouter()
 	static.Use(handlers.CompressHandler)
@@ -67,6 +75,7 @@ func NewHTTPd(url string, site site.API, hub *SocketHub, cache *util.Cache) *HTT
 	api.Use(handlers.CompressHandler)
 	api.Use(handlers.CORS(
 		handlers.AllowedHeaders([]string{"Content-Type"}),
+		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}),
 	))
 
 	// site api
@@ -74,6 +83,14 @@ func NewHTTPd(url string, site site.API, hub *SocketHub, cache *util.Cache) *HTT
 		api.Methods(r.Methods...).Path(r.Pattern).Handler(r.HandlerFunc)
 	}
 
+	// versioned api
+	v1 := api.PathPrefix("/v1").Subrouter()
+	v1Routes := v1Routes(site)
+	for _, r := range v1Routes {
+		v1.Methods(r.Method, "OPTIONS").Path(r.Pattern).Handler(r.Handler)
+	}
+	v1.Methods("GET").Path("/openapi.json").Handler(openAPIHandler("/api/v1", v1Routes))
+
 	// loadpoint api
 	for id, lp := range site.LoadPoints() {
 		lpAPI := api.PathPrefix(fmt.Sprintf("/loadpoints/%d", id)).Subrouter()
@@ -115,3 +132,8 @@ func NewHTTPd(url string, site site.API, hub *SocketHub, cache *util.Cache) *HTT
 func (s *HTTPd) Router() *mux.Router {
 	return s.Handler.(*mux.Router)
 }
+
+// RegisterReload adds the configuration reload api
+func (s *HTTPd) RegisterReload(reload func() error) {
+	s.Router().Methods("POST").Path("/api/config/reload").Handler(jsonHandler(reloadHandler(reload)))
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/evcc-io/evcc/util/templates"
+	"github.com/gorilla/mux"
+)
+
+// DeviceConfig is a template based device configuration
+type DeviceConfig struct {
+	Name     string                 `json:"name"`
+	Template string                 `json:"template"`
+	Values   map[string]interface{} `json:"values"`
+}
+
+// MaskedValue replaces masked template values like passwords in responses.
+// Updates sending it back keep the stored value.
+const MaskedValue = "***"
+
+// ErrDeviceNotFound indicates that the managed device does not exist
+var ErrDeviceNotFound = errors.New("device not found")
+
+// ConfigManager tests and persists template based device configurations
+type ConfigManager interface {
+	// Test creates the device and reads its values
+	Test(class string, conf DeviceConfig) (string, error)
+	// Devices returns the managed devices of the class
+	Devices(class string) ([]DeviceConfig, error)
+	// Add adds the managed device and returns if a restart is required to apply it
+	Add(class string, conf DeviceConfig) (bool, error)
+	// Update replaces the managed device and returns if a restart is required to apply it.
+	// Returns ErrDeviceNotFound if the device does not exist.
+	Update(class string, conf DeviceConfig) (bool, error)
+	// Delete removes the managed device and returns if a restart is required to apply it.
+	// Returns ErrDeviceNotFound if the device does not exist.
+	Delete(class, name string) (bool, error)
+}
+
+// TemplateResponse is a device template
+type TemplateResponse struct {
+	Template     string          `json:"template"`
+	Titles       []string        `json:"titles"`
+	Group        string          `json:"group,omitempty"`
+	Capabilities []string        `json:"capabilities,omitempty"`
+	Params       []TemplateParam `json:"params"`
+}
+
+// TemplateParam is a device template parameter
+type TemplateParam struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Help        string   `json:"help,omitempty"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default,omitempty"`
+	Example     string   `json:"example,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Advanced    bool     `json:"advanced,omitempty"`
+	Mask        bool     `json:"mask,omitempty"`
+	Choices     []string `json:"choices,omitempty"`
+}
+
+// DeviceTestResponse is the result of a device test
+type DeviceTestResponse struct {
+	Result string `json:"result"`
+}
+
+// RenderResponse is the rendered device configuration
+type RenderResponse struct {
+	Result string `json:"result"`
+}
+
+// ConfigSaveResponse is the result of a configuration change
+type ConfigSaveResponse struct {
+	Restart bool `json:"restart"` // restart required to apply the change
+}
+
+// templateResponse converts the template to its api representation
+func templateResponse(tmpl templates.Template, lang string) TemplateResponse {
+	res := TemplateResponse{
+		Template:     tmpl.Template,
+		Titles:       tmpl.Titles(lang),
+		Group:        tmpl.GroupTitle(),
+		Capabilities: tmpl.Capabilities,
+	}
+
+	for _, p := range tmpl.Params {
+		if p.Hidden || p.Deprecated {
+			continue
+		}
+
+		choices := p.Choice
+		if len(choices) == 0 {
+			choices = p.ValidValues
+		}
+
+		typ := p.ValueType
+		if typ == "" {
+			typ = templates.ParamValueTypeString
+		}
+
+		res.Params = append(res.Params, TemplateParam{
+			Name:        p.Name,
+			Description: p.Description.String(lang),
+			Help:        p.Help.String(lang),
+			Type:        typ,
+			Default:     p.Default,
+			Example:     p.Example,
+			Required:    p.Required,
+			Advanced:    p.Advanced,
+			Mask:        p.Mask,
+			Choices:     choices,
+		})
+
+		// modbus connection parameters depend on the selected interface
+		if p.Name == templates.ParamModbus {
+			res.Params = append(res.Params, modbusParams(p)...)
+		}
+	}
+
+	return res
+}
+
+// modbusParams returns the connection parameters of a modbus device
+func modbusParams(p templates.Param) []TemplateParam {
+	def := func(i int) string {
+		if i == 0 {
+			return ""
+		}
+		return strconv.Itoa(i)
+	}
+
+	return []TemplateParam{
+		{Name: templates.ModbusParamNameId, Type: templates.ParamValueTypeNumber, Default: def(p.ID)},
+		{Name: templates.ModbusParamNameHost, Type: templates.ParamValueTypeString},
+		{Name: templates.ModbusParamNamePort, Type: templates.ParamValueTypeNumber, Default: def(p.Port)},
+		{Name: templates.ModbusParamNameDevice, Type: templates.ParamValueTypeString},
+		{Name: templates.ModbusParamNameBaudrate, Type: templates.ParamValueTypeNumber, Default: def(p.Baudrate)},
+		{Name: templates.ModbusParamNameComset, Type: templates.ParamValueTypeString, Default: p.Comset},
+	}
+}
+
+// templatesHandler returns all templates of the device class
+func templatesHandler(w http.ResponseWriter, r *http.Request) {
+	lang := r.URL.Query().Get("lang")
+
+	res := make([]TemplateResponse, 0)
+	for _, tmpl := range templates.ByClass(mux.Vars(r)["class"]) {
+		res = append(res, templateResponse(tmpl, lang))
+	}
+
+	jsonResponse(w, res)
+}
+
+// templateHandler returns a single template
+func templateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	tmpl, err := templates.ByTemplate(vars["template"], vars["class"])
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err)
+		return
+	}
+
+	jsonResponse(w, templateResponse(tmpl, r.URL.Query().Get("lang")))
+}
+
+// renderHandler renders the device configuration from its template
+func renderHandler(w http.ResponseWriter, r *http.Request) {
+	var req DeviceConfig
+	if err := jsonDecode(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tmpl, err := templates.ByTemplate(req.Template, mux.Vars(r)["class"])
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err)
+		return
+	}
+
+	b, _, err := tmpl.RenderResult(templates.TemplateRenderModeInstance, req.Values)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	jsonResponse(w, RenderResponse{Result: string(b)})
+}
+
+// deviceTestHandler creates the device and reads its values
+func deviceTestHandler(mgr ConfigManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req DeviceConfig
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		res, err := mgr.Test(mux.Vars(r)["class"], req)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		jsonResponse(w, DeviceTestResponse{Result: res})
+	}
+}
+
+// configDevicesHandler returns the managed devices of the class
+func configDevicesHandler(mgr ConfigManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := mgr.Devices(mux.Vars(r)["class"])
+		if err != nil {
+			jsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		jsonResponse(w, res)
+	}
+}
+
+// configErrorStatus returns the http status of a configuration error
+func configErrorStatus(err error) int {
+	if errors.Is(err, ErrDeviceNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// configAddHandler adds a managed device
+func configAddHandler(mgr ConfigManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req DeviceConfig
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		restart, err := mgr.Add(mux.Vars(r)["class"], req)
+		if err != nil {
+			jsonError(w, configErrorStatus(err), err)
+			return
+		}
+
+		jsonResponse(w, ConfigSaveResponse{Restart: restart})
+	}
+}
+
+// configUpdateHandler updates an existing managed device
+func configUpdateHandler(mgr ConfigManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req DeviceConfig
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// update requires matching name
+		name := mux.Vars(r)["name"]
+		if req.Name != "" && req.Name != name {
+			jsonError(w, http.StatusBadRequest, errors.New("device name cannot be changed"))
+			return
+		}
+		req.Name = name
+
+		restart, err := mgr.Update(mux.Vars(r)["class"], req)
+		if err != nil {
+			jsonError(w, configErrorStatus(err), err)
+			return
+		}
+
+		jsonResponse(w, ConfigSaveResponse{Restart: restart})
+	}
+}
+
+// configDeleteHandler removes a managed device
+func configDeleteHandler(mgr ConfigManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		restart, err := mgr.Delete(vars["class"], vars["name"])
+		if err != nil {
+			jsonError(w, configErrorStatus(err), err)
+			return
+		}
+
+		jsonResponse(w, ConfigSaveResponse{Restart: restart})
+	}
+}
+
+// RegisterConfig adds the template based device configuration api
+func (s *HTTPd) RegisterConfig(mgr ConfigManager) {
+	routes := map[string]route{
+		"templates": {[]string{"GET"}, "/templates/{class:charger|meter|vehicle}", templatesHandler},
+		"template":  {[]string{"GET"}, "/templates/{class:charger|meter|vehicle}/{template}", templateHandler},
+		"render":    {[]string{"POST"}, "/render/{class:charger|meter|vehicle}", renderHandler},
+		"test":      {[]string{"POST"}, "/test/{class:charger|meter|vehicle}", deviceTestHandler(mgr)},
+		"devices":   {[]string{"GET"}, "/devices/{class:charger|meter|vehicle}", configDevicesHandler(mgr)},
+		"add":       {[]string{"POST"}, "/devices/{class:charger|meter|vehicle}", configAddHandler(mgr)},
+		"update":    {[]string{"PUT"}, "/devices/{class:charger|meter|vehicle}/{name}", configUpdateHandler(mgr)},
+		"delete":    {[]string{"DELETE"}, "/devices/{class:charger|meter|vehicle}/{name}", configDeleteHandler(mgr)},
+	}
+
+	for _, r := range routes {
+		s.Router().Methods(r.Methods...).Path("/api/config" + r.Pattern).Handler(jsonHandler(r.HandlerFunc))
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type testConfigManager struct {
+	ConfigManager
+	devices map[string]DeviceConfig
+}
+
+func (m *testConfigManager) Add(class string, conf DeviceConfig) (bool, error) {
+	if _, ok := m.devices[conf.Name]; ok {
+		return false, fmt.Errorf("duplicate %s name: %s", class, conf.Name)
+	}
+	m.devices[conf.Name] = conf
+	return false, nil
+}
+
+func (m *testConfigManager) Update(class string, conf DeviceConfig) (bool, error) {
+	if _, ok := m.devices[conf.Name]; !ok {
+		return false, fmt.Errorf("%s %s: %w", class, conf.Name, ErrDeviceNotFound)
+	}
+	m.devices[conf.Name] = conf
+	return false, nil
+}
+
+func TestConfigSaveHandlers(t *testing.T) {
+	mgr := &testConfigManager{devices: make(map[string]DeviceConfig)}
+
+	router := mux.NewRouter()
+	router.Methods("POST").Path("/devices/{class}").Handler(configAddHandler(mgr))
+	router.Methods("PUT").Path("/devices/{class}/{name}").Handler(configUpdateHandler(mgr))
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{"PUT", "/devices/charger/wallbox", `{"template":"demo"}`, http.StatusNotFound},
+		{"POST", "/devices/charger", `{"name":"wallbox","template":"demo"}`, http.StatusOK},
+		{"POST", "/devices/charger", `{"name":"wallbox","template":"demo"}`, http.StatusBadRequest},
+		{"PUT", "/devices/charger/wallbox", `{"template":"other"}`, http.StatusOK},
+		{"PUT", "/devices/charger/wallbox", `{"name":"other","template":"demo"}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+		if w.Code != tc.status {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, w.Code)
+		}
+	}
+
+	if len(mgr.devices) != 1 || mgr.devices["wallbox"].Template != "other" {
+		t.Errorf("unexpected devices: %+v", mgr.devices)
+	}
+}
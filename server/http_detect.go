@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/evcc-io/evcc/detect"
+	"github.com/evcc-io/evcc/util"
+)
+
+// DetectRequest starts a network scan. Without hosts, the local subnet is scanned.
+type DetectRequest struct {
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// DetectResponse is the state of the network scan
+type DetectResponse struct {
+	Running     bool                `json:"running"`
+	Updated     *time.Time          `json:"updated,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	Suggestions []detect.Suggestion `json:"suggestions"`
+}
+
+// detector runs network scans in the background since they exceed the request timeout
+type detector struct {
+	mu  sync.Mutex
+	log *util.Logger
+	res DetectResponse
+}
+
+func newDetector() *detector {
+	return &detector{
+		log: util.NewLogger("detect"),
+		res: DetectResponse{Suggestions: make([]detect.Suggestion, 0)},
+	}
+}
+
+// run executes the scan and stores its results
+func (d *detector) run(hosts []string) {
+	res := DetectResponse{Suggestions: make([]detect.Suggestion, 0)}
+
+	if hosts, err := detect.Hosts(d.log, hosts); err == nil {
+		res.Suggestions = append(res.Suggestions, detect.Suggest(detect.Work(d.log, 50, hosts))...)
+	} else {
+		res.Error = err.Error()
+	}
+
+	now := time.Now()
+	res.Updated = &now
+
+	d.mu.Lock()
+	d.res = res
+	d.mu.Unlock()
+}
+
+// response returns the current scan state
+func (d *detector) response() DetectResponse {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.res
+}
+
+// startHandler starts a network scan unless already running
+func (d *detector) startHandler(w http.ResponseWriter, r *http.Request) {
+	var req DetectRequest
+	if err := jsonDecode(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		jsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	d.mu.Lock()
+	if !d.res.Running {
+		d.res.Running = true
+		go d.run(req.Hosts)
+	}
+	res := d.res
+	d.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+	jsonWrite(w, res)
+}
+
+// resultHandler returns the results of the last network scan
+func (d *detector) resultHandler(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, d.response())
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/devices"
 	"github.com/evcc-io/evcc/core/loadpoint"
 	"github.com/evcc-io/evcc/core/site"
 	"github.com/evcc-io/evcc/util"
@@ -63,7 +64,7 @@ func jsonResult(w http.ResponseWriter, res interface{}) {
 
 func jsonError(w http.ResponseWriter, status int, err error) {
 	w.WriteHeader(status)
-	jsonWrite(w, map[string]interface{}{"error": err.Error()})
+	jsonWrite(w, ErrorResponse{Error: err.Error(), Status: status})
 }
 
 // healthHandler returns current charge mode
@@ -90,6 +91,25 @@ func stateHandler(cache *util.Cache) http.HandlerFunc {
 	}
 }
 
+// devicesHandler returns the health status of all devices
+func devicesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResult(w, devices.Instance.Devices())
+	}
+}
+
+// reloadHandler reloads the configuration file
+func reloadHandler(reload func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := reload(); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		jsonResult(w, true)
+	}
+}
+
 // chargeModeHandler updates charge mode
 func chargeModeHandler(lp loadpoint.API) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -289,3 +309,10 @@ func socketHandler(hub *SocketHub) http.HandlerFunc {
 		ServeWebsocket(hub, w, r)
 	}
 }
+
+// eventsHandler attaches server-sent events handler to uri
+func eventsHandler(hub *SocketHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ServeEvents(hub, w, r)
+	}
+}
@@ -0,0 +1,491 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/core/site"
+	"github.com/gorilla/mux"
+)
+
+// ErrorResponse is the error object returned by all api endpoints
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// SiteResponse is the site state
+type SiteResponse struct {
+	Title       string              `json:"title"`
+	Healthy     bool                `json:"healthy"`
+	PrioritySoC float64             `json:"prioritySoC"`
+	LoadPoints  []LoadPointResponse `json:"loadpoints"`
+}
+
+// SiteRequest updates site settings. Omitted fields remain unchanged.
+type SiteRequest struct {
+	PrioritySoC *float64 `json:"prioritySoC,omitempty"`
+}
+
+// LoadPointResponse is the loadpoint state
+type LoadPointResponse struct {
+	ID                int              `json:"id"`
+	Title             string           `json:"title"`
+	Mode              api.ChargeMode   `json:"mode" enum:"off,now,minpv,pv"`
+	Status            api.ChargeStatus `json:"status" enum:"A,B,C,D,E,F"`
+	Phases            int              `json:"phases"`
+	MinCurrent        float64          `json:"minCurrent"`
+	MaxCurrent        float64          `json:"maxCurrent"`
+	MinSoC            int              `json:"minSoC"`
+	TargetSoC         int              `json:"targetSoC"`
+	TargetTime        *time.Time       `json:"targetTime,omitempty"`
+	ChargePower       float64          `json:"chargePower"`       // W
+	RemainingDuration int64            `json:"remainingDuration"` // s
+	RemainingEnergy   float64          `json:"remainingEnergy"`   // Wh
+	Vehicle           *VehicleResponse `json:"vehicle,omitempty"`
+}
+
+// LoadPointRequest updates loadpoint settings. Omitted fields remain unchanged.
+type LoadPointRequest struct {
+	Mode       *api.ChargeMode `json:"mode,omitempty" enum:"off,now,minpv,pv"`
+	MinSoC     *int            `json:"minSoC,omitempty"`
+	TargetSoC  *int            `json:"targetSoC,omitempty"`
+	MinCurrent *float64        `json:"minCurrent,omitempty"`
+	MaxCurrent *float64        `json:"maxCurrent,omitempty"`
+	Phases     *int            `json:"phases,omitempty"`
+}
+
+// TargetChargeRequest sets the target soc to be reached at given time
+type TargetChargeRequest struct {
+	SoC  int       `json:"soc"`
+	Time time.Time `json:"time"`
+}
+
+// RemoteDemandRequest sets the remote demand
+type RemoteDemandRequest struct {
+	Demand loadpoint.RemoteDemand `json:"demand" enum:",soft,hard"`
+	Source string                 `json:"source"`
+}
+
+// VehicleResponse is the vehicle state
+type VehicleResponse struct {
+	Title     string   `json:"title"`
+	Capacity  int64    `json:"capacity"` // kWh
+	LoadPoint int      `json:"loadpoint"`
+	Active    bool     `json:"active"`
+	SoC       *float64 `json:"soc,omitempty"`
+}
+
+// TariffsResponse is the current tariff state
+type TariffsResponse struct {
+	Currency string          `json:"currency"`
+	Grid     *TariffResponse `json:"grid,omitempty"`
+	FeedIn   *TariffResponse `json:"feedIn,omitempty"`
+	CO2      *CO2Response    `json:"co2,omitempty"`
+}
+
+// TariffResponse is the current price of a tariff
+type TariffResponse struct {
+	Price float64 `json:"price"`
+	Cheap bool    `json:"cheap"`
+}
+
+// CO2Response is the current grid co2 intensity
+type CO2Response struct {
+	Intensity float64    `json:"intensity"` // gCO2/kWh
+	Clean     bool       `json:"clean"`
+	Forecast  []api.Rate `json:"forecast,omitempty"`
+}
+
+// apiRoute is a documented api endpoint
+type apiRoute struct {
+	Method   string
+	Pattern  string
+	Summary  string
+	Request  interface{}
+	Response interface{}
+	Handler  http.HandlerFunc
+}
+
+// v1Routes returns the versioned api endpoints
+func v1Routes(site site.API) []apiRoute {
+	return []apiRoute{
+		{"GET", "/site", "Get site state", nil, SiteResponse{}, v1SiteHandler(site)},
+		{"PATCH", "/site", "Update site settings", SiteRequest{}, SiteResponse{}, v1SiteUpdateHandler(site)},
+		{"GET", "/loadpoints", "List loadpoints", nil, []LoadPointResponse{}, v1LoadPointsHandler(site)},
+		{"GET", "/loadpoints/{id:[0-9]+}", "Get loadpoint state", nil, LoadPointResponse{}, v1LoadPointHandler(site)},
+		{"PATCH", "/loadpoints/{id:[0-9]+}", "Update loadpoint settings", LoadPointRequest{}, LoadPointResponse{}, v1LoadPointUpdateHandler(site)},
+		{"PUT", "/loadpoints/{id:[0-9]+}/targetcharge", "Set target charge", TargetChargeRequest{}, LoadPointResponse{}, v1TargetChargeHandler(site)},
+		{"DELETE", "/loadpoints/{id:[0-9]+}/targetcharge", "Remove target charge", nil, LoadPointResponse{}, v1TargetChargeRemoveHandler(site)},
+		{"PUT", "/loadpoints/{id:[0-9]+}/remotedemand", "Set remote demand", RemoteDemandRequest{}, RemoteDemandRequest{}, v1RemoteDemandHandler(site)},
+		{"DELETE", "/loadpoints/{id:[0-9]+}/vehicle", "Remove active vehicle", nil, LoadPointResponse{}, v1VehicleRemoveHandler(site)},
+		{"GET", "/vehicles", "List vehicles", nil, []VehicleResponse{}, v1VehiclesHandler(site)},
+		{"GET", "/tariffs", "Get current tariffs", nil, TariffsResponse{}, v1TariffsHandler(site)},
+	}
+}
+
+// jsonDecode decodes the request body rejecting unknown fields
+func jsonDecode(r *http.Request, req interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(req); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	return nil
+}
+
+// jsonResponse writes the typed response
+func jsonResponse(w http.ResponseWriter, res interface{}) {
+	w.WriteHeader(http.StatusOK)
+	jsonWrite(w, res)
+}
+
+// loadPointByID returns the loadpoint identified by the request's id
+func loadPointByID(site site.API, r *http.Request) (int, loadpoint.API, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lps := site.LoadPoints()
+	if id < 0 || id >= len(lps) {
+		return 0, nil, fmt.Errorf("loadpoint not found: %d", id)
+	}
+
+	return id, lps[id], nil
+}
+
+// loadPointHandler wraps loadpoint handlers resolving the loadpoint from the request
+func loadPointHandler(site site.API, fun func(http.ResponseWriter, *http.Request, int, loadpoint.API)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, lp, err := loadPointByID(site, r)
+		if err != nil {
+			jsonError(w, http.StatusNotFound, err)
+			return
+		}
+
+		fun(w, r, id, lp)
+	}
+}
+
+func vehicleResponse(id int, lp loadpoint.API, vehicle api.Vehicle) VehicleResponse {
+	res := VehicleResponse{
+		Title:     vehicle.Title(),
+		Capacity:  vehicle.Capacity(),
+		LoadPoint: id,
+	}
+
+	if active := lp.GetVehicle(); active != nil && active.Title() == vehicle.Title() {
+		soc := lp.GetVehicleSoC()
+		res.Active = true
+		res.SoC = &soc
+	}
+
+	return res
+}
+
+func loadPointResponse(id int, lp loadpoint.API) LoadPointResponse {
+	res := LoadPointResponse{
+		ID:                id,
+		Title:             lp.Name(),
+		Mode:              lp.GetMode(),
+		Status:            lp.GetStatus(),
+		Phases:            lp.GetPhases(),
+		MinCurrent:        lp.GetMinCurrent(),
+		MaxCurrent:        lp.GetMaxCurrent(),
+		MinSoC:            lp.GetMinSoC(),
+		TargetSoC:         lp.GetTargetSoC(),
+		ChargePower:       lp.GetChargePower(),
+		RemainingDuration: int64(lp.GetRemainingDuration().Seconds()),
+		RemainingEnergy:   lp.GetRemainingEnergy(),
+	}
+
+	if ts := lp.GetTargetTime(); !ts.IsZero() {
+		res.TargetTime = &ts
+	}
+
+	if vehicle := lp.GetVehicle(); vehicle != nil {
+		v := vehicleResponse(id, lp, vehicle)
+		res.Vehicle = &v
+	}
+
+	return res
+}
+
+func siteResponse(site site.API) SiteResponse {
+	res := SiteResponse{
+		Title:       site.GetTitle(),
+		Healthy:     site.Healthy(),
+		PrioritySoC: site.GetPrioritySoC(),
+		LoadPoints:  []LoadPointResponse{},
+	}
+
+	for id, lp := range site.LoadPoints() {
+		res.LoadPoints = append(res.LoadPoints, loadPointResponse(id, lp))
+	}
+
+	return res
+}
+
+// v1SiteHandler returns the site state
+func v1SiteHandler(site site.API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, siteResponse(site))
+	}
+}
+
+// v1SiteUpdateHandler updates site settings
+func v1SiteUpdateHandler(site site.API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req SiteRequest
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if req.PrioritySoC != nil {
+			if err := site.SetPrioritySoC(*req.PrioritySoC); err != nil {
+				jsonError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		jsonResponse(w, siteResponse(site))
+	}
+}
+
+// v1LoadPointsHandler returns all loadpoints
+func v1LoadPointsHandler(site site.API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := []LoadPointResponse{}
+		for id, lp := range site.LoadPoints() {
+			res = append(res, loadPointResponse(id, lp))
+		}
+
+		jsonResponse(w, res)
+	}
+}
+
+// v1LoadPointHandler returns the loadpoint state
+func v1LoadPointHandler(site site.API) http.HandlerFunc {
+	return loadPointHandler(site, func(w http.ResponseWriter, r *http.Request, id int, lp loadpoint.API) {
+		jsonResponse(w, loadPointResponse(id, lp))
+	})
+}
+
+// validate checks the requested settings against each other and the loadpoint's current limits
+func (req LoadPointRequest) validate(minCurrent, maxCurrent float64) error {
+	if req.MinSoC != nil && (*req.MinSoC < 0 || *req.MinSoC > 100) {
+		return fmt.Errorf("invalid min soc: %d", *req.MinSoC)
+	}
+
+	if req.TargetSoC != nil && (*req.TargetSoC < 0 || *req.TargetSoC > 100) {
+		return fmt.Errorf("invalid target soc: %d", *req.TargetSoC)
+	}
+
+	if req.MinCurrent != nil || req.MaxCurrent != nil {
+		if req.MinCurrent != nil {
+			minCurrent = *req.MinCurrent
+		}
+		if req.MaxCurrent != nil {
+			maxCurrent = *req.MaxCurrent
+		}
+
+		if minCurrent <= 0 || maxCurrent < minCurrent {
+			return fmt.Errorf("invalid current range: %.3gA-%.3gA", minCurrent, maxCurrent)
+		}
+	}
+
+	if req.Phases != nil && *req.Phases != 1 && *req.Phases != 3 {
+		return fmt.Errorf("invalid phases: %d", *req.Phases)
+	}
+
+	return nil
+}
+
+// v1LoadPointUpdateHandler updates loadpoint settings
+func v1LoadPointUpdateHandler(site site.API) http.HandlerFunc {
+	return loadPointHandler(site, func(w http.ResponseWriter, r *http.Request, id int, lp loadpoint.API) {
+		var req LoadPointRequest
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// validate before applying any changes
+		var mode api.ChargeMode
+		if req.Mode != nil {
+			var err error
+			if mode, err = api.ChargeModeString(string(*req.Mode)); err != nil || mode == api.ModeEmpty {
+				jsonError(w, http.StatusBadRequest, fmt.Errorf("invalid mode: %s", *req.Mode))
+				return
+			}
+		}
+
+		if err := req.validate(lp.GetMinCurrent(), lp.GetMaxCurrent()); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// phase switching may fail at the charger, apply first to leave remaining settings unchanged
+		if req.Phases != nil {
+			if err := lp.SetPhases(*req.Phases); err != nil {
+				jsonError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		if req.Mode != nil {
+			lp.SetMode(mode)
+		}
+		if req.MinSoC != nil {
+			lp.SetMinSoC(*req.MinSoC)
+		}
+		if req.TargetSoC != nil {
+			lp.SetTargetSoC(*req.TargetSoC)
+		}
+		if req.MinCurrent != nil {
+			lp.SetMinCurrent(*req.MinCurrent)
+		}
+		if req.MaxCurrent != nil {
+			lp.SetMaxCurrent(*req.MaxCurrent)
+		}
+
+		jsonResponse(w, loadPointResponse(id, lp))
+	})
+}
+
+// v1TargetChargeHandler sets the target charge
+func v1TargetChargeHandler(site site.API) http.HandlerFunc {
+	return loadPointHandler(site, func(w http.ResponseWriter, r *http.Request, id int, lp loadpoint.API) {
+		var req TargetChargeRequest
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if req.Time.IsZero() || req.SoC <= 0 || req.SoC > 100 {
+			jsonError(w, http.StatusBadRequest, errors.New("invalid target charge"))
+			return
+		}
+
+		lp.SetTargetCharge(req.Time, req.SoC)
+
+		jsonResponse(w, loadPointResponse(id, lp))
+	})
+}
+
+// v1TargetChargeRemoveHandler removes the target charge
+func v1TargetChargeRemoveHandler(site site.API) http.HandlerFunc {
+	return loadPointHandler(site, func(w http.ResponseWriter, r *http.Request, id int, lp loadpoint.API) {
+		lp.SetTargetCharge(time.Time{}, 0)
+		jsonResponse(w, loadPointResponse(id, lp))
+	})
+}
+
+// v1RemoteDemandHandler sets the remote demand
+func v1RemoteDemandHandler(site site.API) http.HandlerFunc {
+	return loadPointHandler(site, func(w http.ResponseWriter, r *http.Request, id int, lp loadpoint.API) {
+		var req RemoteDemandRequest
+		if err := jsonDecode(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		demand, err := loadpoint.RemoteDemandString(string(req.Demand))
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		lp.RemoteControl(req.Source, demand)
+
+		jsonResponse(w, RemoteDemandRequest{Demand: demand, Source: req.Source})
+	})
+}
+
+// v1VehicleRemoveHandler removes the active vehicle
+func v1VehicleRemoveHandler(site site.API) http.HandlerFunc {
+	return loadPointHandler(site, func(w http.ResponseWriter, r *http.Request, id int, lp loadpoint.API) {
+		lp.SetVehicle(nil)
+		jsonResponse(w, loadPointResponse(id, lp))
+	})
+}
+
+// v1VehiclesHandler returns the vehicles assigned to all loadpoints
+func v1VehiclesHandler(site site.API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := []VehicleResponse{}
+		for id, lp := range site.LoadPoints() {
+			for _, vehicle := range lp.GetVehicles() {
+				res = append(res, vehicleResponse(id, lp, vehicle))
+			}
+		}
+
+		jsonResponse(w, res)
+	}
+}
+
+func tariffResponse(t api.Tariff) *TariffResponse {
+	if t == nil {
+		return nil
+	}
+
+	price, err := t.CurrentPrice()
+	if err != nil {
+		log.ERROR.Printf("httpd: tariff: %v", err)
+		return nil
+	}
+
+	cheap, err := t.IsCheap()
+	if err != nil {
+		log.ERROR.Printf("httpd: tariff: %v", err)
+		return nil
+	}
+
+	return &TariffResponse{Price: price, Cheap: cheap}
+}
+
+func co2Response(t api.CO2Intensity) *CO2Response {
+	if t == nil {
+		return nil
+	}
+
+	intensity, err := t.CurrentIntensity()
+	if err != nil {
+		log.ERROR.Printf("httpd: co2: %v", err)
+		return nil
+	}
+
+	clean, err := t.IsClean()
+	if err != nil {
+		log.ERROR.Printf("httpd: co2: %v", err)
+		return nil
+	}
+
+	// forecast is optional
+	forecast, _ := t.Forecast()
+
+	return &CO2Response{Intensity: intensity, Clean: clean, Forecast: forecast}
+}
+
+// v1TariffsHandler returns the current tariffs. Unavailable tariffs are omitted.
+func v1TariffsHandler(site site.API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tariffs := site.GetTariffs()
+
+		jsonResponse(w, TariffsResponse{
+			Currency: tariffs.Currency.String(),
+			Grid:     tariffResponse(tariffs.Grid),
+			FeedIn:   tariffResponse(tariffs.FeedIn),
+			CO2:      co2Response(tariffs.CO2),
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLoadPointRequestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		req string
+		ok  bool
+	}{
+		{`{}`, true},
+		{`{"minSoC":0,"targetSoC":100}`, true},
+		{`{"minSoC":-1}`, false},
+		{`{"targetSoC":101}`, false},
+		{`{"minCurrent":8}`, true},
+		{`{"minCurrent":0}`, false},
+		{`{"minCurrent":20}`, false},
+		{`{"maxCurrent":4}`, false},
+		{`{"minCurrent":10,"maxCurrent":32}`, true},
+		{`{"minCurrent":20,"maxCurrent":10}`, false},
+		{`{"phases":3}`, true},
+		{`{"phases":2,"minSoC":20}`, false},
+	} {
+		var req LoadPointRequest
+		if err := json.Unmarshal([]byte(tc.req), &req); err != nil {
+			t.Fatal(err)
+		}
+
+		// loadpoint configured 6-16A
+		if err := req.validate(6, 16); (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok %v, got %v", tc.req, tc.ok, err)
+		}
+	}
+}
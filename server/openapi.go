@@ -0,0 +1,195 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// pathParam matches mux path variables including optional regexp
+var pathParam = regexp.MustCompile(`{([^:}]+)(:[^}]+)?}`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openAPI generates the OpenAPI document from the api routes
+type openAPI struct {
+	schemas map[string]interface{}
+}
+
+// schema returns the json schema of the given type. Structs are registered as components.
+func (o *openAPI) schema(typ reflect.Type) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+
+	case typ.Kind() == reflect.Struct:
+		if _, ok := o.schemas[typ.Name()]; !ok {
+			// register before recursing into fields
+			o.schemas[typ.Name()] = nil
+			o.schemas[typ.Name()] = o.object(typ)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + typ.Name()}
+
+	case typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": o.schema(typ.Elem())}
+
+	case typ.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": o.schema(typ.Elem())}
+
+	case typ.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case typ.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// object returns the json schema of a struct using its json tags
+func (o *openAPI) object(typ reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if segs := strings.SplitN(tag, ",", 2); segs[0] != "" {
+				name = segs[0]
+				if len(segs) > 1 {
+					opts = segs[1]
+				}
+			} else if len(segs) > 1 {
+				opts = segs[1]
+			}
+		}
+
+		prop := o.schema(f.Type)
+		if enum, ok := f.Tag.Lookup("enum"); ok {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+		props[name] = prop
+
+		if f.Type.Kind() != reflect.Ptr && !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	res := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+
+	if len(required) > 0 {
+		res["required"] = required
+	}
+
+	return res
+}
+
+// content returns the json media type of the given value
+func (o *openAPI) content(v interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": o.schema(reflect.TypeOf(v)),
+		},
+	}
+}
+
+// document creates the OpenAPI document for the routes served below prefix
+func (o *openAPI) document(prefix string, routes []apiRoute) map[string]interface{} {
+	paths := make(map[string]map[string]interface{})
+
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content":     o.content(ErrorResponse{}),
+	}
+
+	for _, r := range routes {
+		op := map[string]interface{}{
+			"summary": r.Summary,
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "OK",
+					"content":     o.content(r.Response),
+				},
+				"default": errorResponse,
+			},
+		}
+
+		var params []interface{}
+		for _, m := range pathParam.FindAllStringSubmatch(r.Pattern, -1) {
+			typ := "string"
+			if strings.Contains(m[2], "[0-9]") {
+				typ = "integer"
+			}
+
+			params = append(params, map[string]interface{}{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": typ},
+			})
+		}
+
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if r.Request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  o.content(r.Request),
+			}
+		}
+
+		path := prefix + pathParam.ReplaceAllString(r.Pattern, "{$1}")
+		if _, ok := paths[path]; !ok {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "evcc",
+			"version": Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": o.schemas,
+		},
+	}
+}
+
+// openAPIHandler serves the OpenAPI document of the routes served below prefix
+func openAPIHandler(prefix string, routes []apiRoute) http.HandlerFunc {
+	o := &openAPI{schemas: make(map[string]interface{})}
+	doc := o.document(prefix, routes)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, doc)
+	}
+}
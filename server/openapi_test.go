@@ -0,0 +1,45 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	routes := []apiRoute{
+		{"PATCH", "/loadpoints/{id:[0-9]+}", "Update loadpoint", LoadPointRequest{}, LoadPointResponse{}, nil},
+	}
+
+	o := &openAPI{schemas: make(map[string]interface{})}
+	doc := o.document("/api/v1", routes)
+
+	paths := doc["paths"].(map[string]map[string]interface{})
+	op, ok := paths["/api/v1/loadpoints/{id}"]["patch"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("missing operation: %v", paths)
+	}
+
+	params := op["parameters"].([]interface{})
+	if len(params) != 1 || params[0].(map[string]interface{})["name"] != "id" {
+		t.Errorf("unexpected parameters: %v", params)
+	}
+
+	for _, name := range []string{"LoadPointRequest", "LoadPointResponse", "VehicleResponse", "ErrorResponse"} {
+		if _, ok := o.schemas[name]; !ok {
+			t.Errorf("missing schema: %s", name)
+		}
+	}
+
+	req := o.schemas["LoadPointRequest"].(map[string]interface{})
+	if _, ok := req["required"]; ok {
+		t.Errorf("request fields must be optional: %v", req["required"])
+	}
+
+	res := o.schemas["LoadPointResponse"].(map[string]interface{})
+	props := res["properties"].(map[string]interface{})
+	if mode := props["mode"].(map[string]interface{}); mode["type"] != "string" || mode["enum"] == nil {
+		t.Errorf("unexpected mode schema: %v", mode)
+	}
+	if ts := props["targetTime"].(map[string]interface{}); ts["format"] != "date-time" {
+		t.Errorf("unexpected targetTime schema: %v", ts)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus exports published site and loadpoint values as prometheus metrics
+type Prometheus struct {
+	loadPoints []loadpoint.API
+	site       map[string]prometheus.Gauge
+	loadpoint  map[string]*prometheus.GaugeVec
+	mode       *prometheus.GaugeVec
+	status     *prometheus.GaugeVec
+}
+
+// NewPrometheus creates prometheus collector and registers its metrics
+func NewPrometheus(reg prometheus.Registerer, loadPoints []loadpoint.API) (*Prometheus, error) {
+	gauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "evcc",
+			Subsystem: "site",
+			Name:      name,
+			Help:      help,
+		})
+	}
+
+	labels := []string{"loadpoint", "title"}
+
+	gaugeVec := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "evcc",
+			Subsystem: "loadpoint",
+			Name:      name,
+			Help:      help,
+		}, labels)
+	}
+
+	m := &Prometheus{
+		loadPoints: loadPoints,
+		site: map[string]prometheus.Gauge{
+			"gridPower":    gauge("grid_power_watts", "Grid power"),
+			"pvPower":      gauge("pv_power_watts", "PV power"),
+			"batteryPower": gauge("battery_power_watts", "Battery power"),
+			"batterySoC":   gauge("battery_soc_percent", "Battery state of charge"),
+			"homePower":    gauge("home_power_watts", "Home power"),
+		},
+		loadpoint: map[string]*prometheus.GaugeVec{
+			"chargePower":   gaugeVec("charge_power_watts", "Charge power", labels...),
+			"chargeCurrent": gaugeVec("charge_current_amperes", "Charge current", labels...),
+			"vehicleSoC":    gaugeVec("vehicle_soc_percent", "Vehicle state of charge", labels...),
+			"enabled":       gaugeVec("enabled", "Charger enabled", labels...),
+		},
+		mode:   gaugeVec("mode", "Active charge mode", append(labels, "mode")...),
+		status: gaugeVec("status", "Active charge status", append(labels, "status")...),
+	}
+
+	for _, g := range m.site {
+		if err := reg.Register(g); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, g := range m.loadpoint {
+		if err := reg.Register(g); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := reg.Register(m.mode); err != nil {
+		return nil, err
+	}
+
+	if err := reg.Register(m.status); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// value converts supported parameter values to float
+func (m *Prometheus) value(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// oneHot sets the gauge of the active value to 1 and all others to 0
+func oneHot(g *prometheus.GaugeVec, labels []string, active string, values ...string) {
+	for _, v := range values {
+		var val float64
+		if v == active {
+			val = 1
+		}
+		g.WithLabelValues(append(labels, v)...).Set(val)
+	}
+}
+
+// Run Prometheus collector
+func (m *Prometheus) Run(in <-chan util.Param) {
+	for p := range in {
+		if p.LoadPoint == nil {
+			if g, ok := m.site[p.Key]; ok {
+				if val, ok := m.value(p.Val); ok {
+					g.Set(val)
+				}
+			}
+			continue
+		}
+
+		id := *p.LoadPoint
+		if id >= len(m.loadPoints) {
+			continue
+		}
+
+		labels := []string{strconv.Itoa(id), m.loadPoints[id].Name()}
+
+		switch p.Key {
+		case "mode":
+			if mode, ok := p.Val.(api.ChargeMode); ok {
+				oneHot(m.mode, labels, string(mode),
+					string(api.ModeOff), string(api.ModeNow), string(api.ModeMinPV), string(api.ModePV))
+			}
+
+		case "status":
+			if status, ok := p.Val.(api.ChargeStatus); ok {
+				oneHot(m.status, labels, string(status),
+					string(api.StatusA), string(api.StatusB), string(api.StatusC),
+					string(api.StatusD), string(api.StatusE), string(api.StatusF))
+			}
+
+		default:
+			if g, ok := m.loadpoint[p.Key]; ok {
+				if val, ok := m.value(p.Val); ok {
+					g.WithLabelValues(labels...).Set(val)
+				}
+			}
+		}
+	}
+}
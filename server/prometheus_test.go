@@ -0,0 +1,66 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/core/loadpoint"
+	"github.com/evcc-io/evcc/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type namedLoadPoint struct {
+	loadpoint.API
+}
+
+func (lp namedLoadPoint) Name() string {
+	return "Garage"
+}
+
+func TestPrometheus(t *testing.T) {
+	m, err := NewPrometheus(prometheus.NewRegistry(), []loadpoint.API{namedLoadPoint{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := 0
+	in := make(chan util.Param)
+	done := make(chan struct{})
+
+	go func() {
+		m.Run(in)
+		close(done)
+	}()
+
+	in <- util.Param{Key: "gridPower", Val: -1000.0}
+	in <- util.Param{Key: "chargePower", Val: 3700.0, LoadPoint: &lp}
+	in <- util.Param{Key: "enabled", Val: true, LoadPoint: &lp}
+	in <- util.Param{Key: "mode", Val: api.ModePV, LoadPoint: &lp}
+	in <- util.Param{Key: "status", Val: api.StatusC, LoadPoint: &lp}
+	close(in)
+	<-done
+
+	if val := testutil.ToFloat64(m.site["gridPower"]); val != -1000 {
+		t.Errorf("gridPower: expected -1000, got %v", val)
+	}
+
+	tc := []struct {
+		gauge  *prometheus.GaugeVec
+		labels []string
+		val    float64
+	}{
+		{m.loadpoint["chargePower"], []string{"0", "Garage"}, 3700},
+		{m.loadpoint["enabled"], []string{"0", "Garage"}, 1},
+		{m.mode, []string{"0", "Garage", "pv"}, 1},
+		{m.mode, []string{"0", "Garage", "now"}, 0},
+		{m.status, []string{"0", "Garage", "C"}, 1},
+		{m.status, []string{"0", "Garage", "B"}, 0},
+	}
+
+	for _, tc := range tc {
+		if val := testutil.ToFloat64(tc.gauge.WithLabelValues(tc.labels...)); val != tc.val {
+			t.Errorf("%v: expected %v, got %v", tc.labels, tc.val, val)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 const (
 	// Time allowed to write a message to the peer
 	socketWriteTimeout = 10 * time.Second
+
+	// Interval of event stream keep-alive comments
+	eventPingInterval = 30 * time.Second
 )
 
 var upgrader = websocket.Upgrader{
@@ -22,15 +27,83 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin:     func(r *http.Request) bool { return true },
 }
 
-// SocketClient is a middleman between the websocket connection and the hub.
+// SocketClient is a middleman between the websocket or event stream connection and the hub.
 type SocketClient struct {
 	hub *SocketHub
 
-	// The websocket connection.
+	// The websocket connection, nil for event stream clients.
 	conn *websocket.Conn
 
 	// Buffered channel of outbound messages.
 	send chan []byte
+
+	// Subscribed parameters.
+	filter *Filter
+}
+
+// Filter restricts the parameters sent to a client
+type Filter struct {
+	keys       []string     // keys or key prefixes ending with *
+	loadpoints map[int]bool // loadpoint ids
+}
+
+// NewFilter creates a filter from the comma-separated keys and loadpoints query parameters.
+// Keys ending with * match as prefix. Loadpoints restrict loadpoint parameters only,
+// site parameters are still sent unless excluded by keys. Returns nil if no filter is given.
+func NewFilter(r *http.Request) (*Filter, error) {
+	q := r.URL.Query()
+	if q.Get("keys") == "" && q.Get("loadpoints") == "" {
+		return nil, nil
+	}
+
+	f := new(Filter)
+
+	for _, key := range strings.Split(q.Get("keys"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			f.keys = append(f.keys, key)
+		}
+	}
+
+	for _, id := range strings.Split(q.Get("loadpoints"), ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+
+		lp, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid loadpoint: %s", id)
+		}
+
+		if f.loadpoints == nil {
+			f.loadpoints = make(map[int]bool)
+		}
+		f.loadpoints[lp] = true
+	}
+
+	return f, nil
+}
+
+// Match checks if the parameter passes the filter
+func (f *Filter) Match(p util.Param) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.loadpoints != nil && p.LoadPoint != nil && !f.loadpoints[*p.LoadPoint] {
+		return false
+	}
+
+	if len(f.keys) == 0 {
+		return true
+	}
+
+	for _, key := range f.keys {
+		if key == p.Key || strings.HasSuffix(key, "*") && strings.HasPrefix(p.Key, strings.TrimSuffix(key, "*")) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // writePump pumps messages from the hub to the websocket connection.
@@ -52,18 +125,101 @@ func (c *SocketClient) writePump() {
 
 // ServeWebsocket handles websocket requests from the peer.
 func ServeWebsocket(hub *SocketHub, w http.ResponseWriter, r *http.Request) {
+	filter, err := NewFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.ERROR.Println(err)
 		return
 	}
-	client := &SocketClient{hub: hub, conn: conn, send: make(chan []byte, 256)}
+	client := &SocketClient{hub: hub, conn: conn, send: make(chan []byte, 256), filter: filter}
 	client.hub.register <- client
 
 	// run writing to client in goroutine
 	go client.writePump()
 }
 
+// ServeEvents handles server-sent events requests from the peer. The connection is hijacked
+// as the server's write timeout would otherwise terminate the stream.
+func ServeEvents(hub *SocketHub, w http.ResponseWriter, r *http.Request) {
+	filter, err := NewFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		log.ERROR.Println(err)
+		return
+	}
+	defer conn.Close()
+
+	write := func(msg string) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout)); err != nil {
+			return err
+		}
+		if _, err := rw.WriteString(msg); err != nil {
+			return err
+		}
+		return rw.Flush()
+	}
+
+	if err := write("HTTP/1.1 200 OK\r\n" +
+		"Content-Type: text/event-stream\r\n" +
+		"Cache-Control: no-cache\r\n" +
+		"Connection: keep-alive\r\n" +
+		"Access-Control-Allow-Origin: *\r\n\r\n"); err != nil {
+		return
+	}
+
+	// detect client disconnect
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, rw)
+		close(closed)
+	}()
+
+	client := &SocketClient{hub: hub, send: make(chan []byte, 256), filter: filter}
+	hub.register <- client
+
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+
+		select {
+		case msg, ok := <-client.send:
+			if !ok {
+				return
+			}
+			err = write("data: " + string(msg) + "\n\n")
+
+		case <-ping.C:
+			err = write(": ping\n\n")
+
+		case <-closed:
+			err = io.EOF
+		}
+
+		if err != nil {
+			hub.unregister <- client
+			return
+		}
+	}
+}
+
 // SocketHub maintains the set of active clients and broadcasts messages to the
 // clients.
 type SocketHub struct {
@@ -133,6 +289,9 @@ func (h *SocketHub) welcome(client *SocketClient, params []util.Param) {
 	var msg strings.Builder
 	msg.WriteString("{")
 	for _, p := range params {
+		if !client.filter.Match(p) {
+			continue
+		}
 		if msg.Len() > 1 {
 			msg.WriteString(",")
 		}
@@ -152,10 +311,16 @@ func (h *SocketHub) broadcast(p util.Param) {
 		msg := "{" + kv(p) + "}"
 
 		for client := range h.clients {
+			if !client.filter.Match(p) {
+				continue
+			}
+
 			select {
 			case client.send <- []byte(msg):
 			default:
-				h.unregister <- client
+				// slow client, unregister directly as the hub cannot send to itself
+				close(client.send)
+				delete(h.clients, client)
 			}
 		}
 	}
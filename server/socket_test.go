@@ -1,9 +1,13 @@
 package server
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/evcc-io/evcc/util"
 )
 
 func TestEncode(t *testing.T) {
@@ -31,3 +35,40 @@ func TestEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestFilter(t *testing.T) {
+	lp := func(id int) *int { return &id }
+
+	tc := []struct {
+		query string
+		param util.Param
+		match bool
+	}{
+		{"", util.Param{Key: "gridPower"}, true},
+		{"keys=gridPower", util.Param{Key: "gridPower"}, true},
+		{"keys=gridPower", util.Param{Key: "pvPower"}, false},
+		{"keys=vehicle*", util.Param{Key: "vehicleSoC", LoadPoint: lp(0)}, true},
+		{"keys=vehicle*", util.Param{Key: "chargePower", LoadPoint: lp(0)}, false},
+		{"loadpoints=1", util.Param{Key: "chargePower", LoadPoint: lp(0)}, false},
+		{"loadpoints=0,1", util.Param{Key: "chargePower", LoadPoint: lp(1)}, true},
+		{"loadpoints=1", util.Param{Key: "gridPower"}, true},
+		{"loadpoints=1&keys=chargePower", util.Param{Key: "gridPower"}, false},
+	}
+
+	for _, tc := range tc {
+		r := httptest.NewRequest(http.MethodGet, "/api/events?"+tc.query, nil)
+
+		f, err := NewFilter(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if match := f.Match(tc.param); match != tc.match {
+			t.Errorf("%s: %+v expected %v, got %v", tc.query, tc.param, tc.match, match)
+		}
+	}
+
+	if _, err := NewFilter(httptest.NewRequest(http.MethodGet, "/api/events?loadpoints=foo", nil)); err == nil {
+		t.Error("expected invalid loadpoint error")
+	}
+}
@@ -19,6 +19,7 @@ type Awattar struct {
 	uri   string
 	cheap float64
 	data  []awattar.PriceInfo
+	done  chan struct{}
 }
 
 var _ api.Tariff = (*Awattar)(nil)
@@ -39,6 +40,7 @@ func NewAwattar(other map[string]interface{}) (*Awattar, error) {
 		log:   util.NewLogger("awattar"),
 		cheap: cc.Cheap,
 		uri:   fmt.Sprintf(awattar.RegionURI, strings.ToLower(cc.Region)),
+		done:  make(chan struct{}),
 	}
 
 	go t.Run()
@@ -49,19 +51,33 @@ func NewAwattar(other map[string]interface{}) (*Awattar, error) {
 func (t *Awattar) Run() {
 	client := request.NewHelper(t.log)
 
-	for ; true; <-time.NewTicker(time.Hour).C {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
 		var res awattar.Prices
 		if err := client.GetJSON(t.uri, &res); err != nil {
 			t.log.ERROR.Println(err)
-			continue
+		} else {
+			t.mux.Lock()
+			t.data = res.Data
+			t.mux.Unlock()
 		}
 
-		t.mux.Lock()
-		t.data = res.Data
-		t.mux.Unlock()
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
+// Close stops the price updates
+func (t *Awattar) Close() error {
+	close(t.done)
+	return nil
+}
+
 func (t *Awattar) CurrentPrice() (float64, error) {
 	t.mux.Lock()
 	defer t.mux.Unlock()
@@ -0,0 +1,189 @@
+package tariff
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+	"github.com/evcc-io/evcc/provider/pipeline"
+	"github.com/evcc-io/evcc/util"
+	"github.com/evcc-io/evcc/util/request"
+)
+
+// CO2 provides grid CO2 intensity loaded from an http endpoint.
+// The (pipeline-processed) response is either a single current value
+// or a json array of {"start","end","value"} forecast slots.
+type CO2 struct {
+	*request.Helper
+	mux      sync.Mutex
+	log      *util.Logger
+	uri      string
+	headers  map[string]string
+	clean    float64
+	interval time.Duration
+	pipeline *pipeline.Pipeline
+	data     []api.Rate
+	done     chan struct{}
+}
+
+var _ api.CO2Intensity = (*CO2)(nil)
+
+// NewCO2FromConfig creates new CO2 intensity source from config
+func NewCO2FromConfig(typ string, other map[string]interface{}) (api.CO2Intensity, error) {
+	switch strings.ToLower(typ) {
+	case "http":
+		return NewCO2(other)
+	default:
+		return nil, errors.New("unknown co2 source: " + typ)
+	}
+}
+
+// NewCO2 creates http CO2 intensity source
+func NewCO2(other map[string]interface{}) (*CO2, error) {
+	cc := struct {
+		URI               string
+		Headers           map[string]string
+		pipeline.Settings `mapstructure:",squash"`
+		Clean             float64 // gCO2/kWh
+		Interval          time.Duration
+	}{
+		Headers:  make(map[string]string),
+		Interval: time.Hour,
+	}
+
+	if err := util.DecodeOther(other, &cc); err != nil {
+		return nil, err
+	}
+
+	if cc.URI == "" {
+		return nil, errors.New("missing uri")
+	}
+
+	if cc.Clean <= 0 {
+		return nil, errors.New("missing clean threshold")
+	}
+
+	log := util.NewLogger("co2")
+
+	pipe, err := pipeline.New(cc.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &CO2{
+		Helper:   request.NewHelper(log),
+		log:      log,
+		uri:      util.DefaultScheme(cc.URI, "https"),
+		headers:  cc.Headers,
+		clean:    cc.Clean,
+		interval: cc.Interval,
+		pipeline: pipe,
+		done:     make(chan struct{}),
+	}
+
+	go t.Run()
+
+	return t, nil
+}
+
+// Run periodically refreshes the intensity data
+func (t *CO2) Run() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if data, err := t.fetch(); err != nil {
+			t.log.ERROR.Println(err)
+		} else {
+			t.mux.Lock()
+			t.data = data
+			t.mux.Unlock()
+		}
+
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops the intensity updates
+func (t *CO2) Close() error {
+	close(t.done)
+	return nil
+}
+
+func (t *CO2) fetch() ([]api.Rate, error) {
+	req, err := request.New(http.MethodGet, t.uri, nil, t.headers)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := t.DoBody(req)
+	if err == nil {
+		b, err = t.pipeline.Process(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRates(b, time.Now(), t.interval)
+}
+
+// parseRates converts a single value or list of slots into rates
+func parseRates(b []byte, now time.Time, interval time.Duration) ([]api.Rate, error) {
+	if f, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64); err == nil {
+		return []api.Rate{{Start: now, End: now.Add(interval), Value: f}}, nil
+	}
+
+	var res []api.Rate
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CurrentIntensity implements the api.CO2Intensity interface
+func (t *CO2) CurrentIntensity() (float64, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	now := time.Now()
+	for _, r := range t.data {
+		if !r.Start.After(now) && r.End.After(now) {
+			return r.Value, nil
+		}
+	}
+
+	return 0, errors.New("unable to find current co2 intensity")
+}
+
+// Forecast implements the api.CO2Intensity interface
+func (t *CO2) Forecast() ([]api.Rate, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	now := time.Now()
+
+	var res []api.Rate
+	for _, r := range t.data {
+		if r.End.After(now) {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+// IsClean implements the api.CO2Intensity interface
+func (t *CO2) IsClean() (bool, error) {
+	val, err := t.CurrentIntensity()
+	return val <= t.clean, err
+}
@@ -0,0 +1,92 @@
+package tariff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evcc-io/evcc/api"
+)
+
+func TestCO2ParseRates(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		in       string
+		expected []api.Rate
+		err      bool
+	}{
+		{"123.4", []api.Rate{{Start: now, End: now.Add(time.Hour), Value: 123.4}}, false},
+		{" 200\n", []api.Rate{{Start: now, End: now.Add(time.Hour), Value: 200}}, false},
+		{`[{"start":"2022-05-01T12:00:00Z","end":"2022-05-01T13:00:00Z","value":150},{"start":"2022-05-01T13:00:00Z","end":"2022-05-01T14:00:00Z","value":250}]`, []api.Rate{
+			{Start: now, End: now.Add(time.Hour), Value: 150},
+			{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), Value: 250},
+		}, false},
+		{"[]", []api.Rate{}, false},
+		{"foo", nil, true},
+	}
+
+	for _, c := range cases {
+		res, err := parseRates([]byte(c.in), now, time.Hour)
+		if (err != nil) != c.err {
+			t.Errorf("%s: unexpected error %v", c.in, err)
+			continue
+		}
+
+		if len(res) != len(c.expected) {
+			t.Errorf("%s: expected %v got %v", c.in, c.expected, res)
+			continue
+		}
+
+		for i, r := range res {
+			if e := c.expected[i]; !r.Start.Equal(e.Start) || !r.End.Equal(e.End) || r.Value != e.Value {
+				t.Errorf("%s: expected %v got %v", c.in, e, r)
+			}
+		}
+	}
+}
+
+func TestCO2Config(t *testing.T) {
+	cases := []struct {
+		other map[string]interface{}
+		err   string
+	}{
+		{map[string]interface{}{"clean": 200}, "missing uri"},
+		{map[string]interface{}{"uri": "localhost"}, "missing clean threshold"},
+		{map[string]interface{}{"uri": "localhost", "clean": 0}, "missing clean threshold"},
+	}
+
+	for _, c := range cases {
+		if _, err := NewCO2(c.other); err == nil || err.Error() != c.err {
+			t.Errorf("%v: expected %s got %v", c.other, c.err, err)
+		}
+	}
+}
+
+func TestCO2IsClean(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		value float64
+		clean bool
+	}{
+		{199, true},
+		{200, true},
+		{201, false},
+	}
+
+	for _, c := range cases {
+		co2 := &CO2{
+			clean: 200,
+			data:  []api.Rate{{Start: now.Add(-time.Minute), End: now.Add(time.Hour), Value: c.value}},
+		}
+
+		if clean, err := co2.IsClean(); err != nil || clean != c.clean {
+			t.Errorf("%.0f: expected %v got %v (%v)", c.value, c.clean, clean, err)
+		}
+	}
+
+	// no current slot
+	if _, err := (&CO2{clean: 200}).IsClean(); err == nil {
+		t.Error("expected error without current intensity")
+	}
+}
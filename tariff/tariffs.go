@@ -1,6 +1,8 @@
 package tariff
 
 import (
+	"io"
+
 	"github.com/evcc-io/evcc/api"
 	"golang.org/x/text/currency"
 )
@@ -9,14 +11,25 @@ type Tariffs struct {
 	Currency currency.Unit
 	Grid     api.Tariff
 	FeedIn   api.Tariff
+	CO2      api.CO2Intensity
 }
 
 var _ api.Tariff = (*Fixed)(nil)
 
-func NewTariffs(currency currency.Unit, grid api.Tariff, feedin api.Tariff) *Tariffs {
+func NewTariffs(currency currency.Unit, grid api.Tariff, feedin api.Tariff, co2 api.CO2Intensity) *Tariffs {
 	t := Tariffs{}
 	t.Currency = currency
 	t.Grid = grid
 	t.FeedIn = feedin
+	t.CO2 = co2
 	return &t
 }
+
+// Close stops the background updates of all tariffs
+func (t Tariffs) Close() {
+	for _, v := range []interface{}{t.Grid, t.FeedIn, t.CO2} {
+		if c, ok := v.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
@@ -23,13 +23,15 @@ type Tibber struct {
 	Cheap  float64
 	client *graphql.Client
 	data   []tibber.PriceInfo
+	done   chan struct{}
 }
 
 var _ api.Tariff = (*Tibber)(nil)
 
 func NewTibber(other map[string]interface{}) (*Tibber, error) {
 	t := &Tibber{
-		log: util.NewLogger("tibber"),
+		log:  util.NewLogger("tibber"),
+		done: make(chan struct{}),
 	}
 
 	if err := util.DecodeOther(other, &t); err != nil {
@@ -72,7 +74,10 @@ func NewTibber(other map[string]interface{}) (*Tibber, error) {
 }
 
 func (t *Tibber) Run() {
-	for ; true; <-time.NewTicker(time.Hour).C {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
 		var res struct {
 			Viewer struct {
 				Home struct {
@@ -89,15 +94,26 @@ func (t *Tibber) Run() {
 
 		if err := t.client.Query(context.Background(), &res, v); err != nil {
 			t.log.ERROR.Println(err)
-			continue
+		} else {
+			t.mux.Lock()
+			t.data = res.Viewer.Home.CurrentSubscription.PriceInfo.Today
+			t.mux.Unlock()
 		}
 
-		t.mux.Lock()
-		t.data = res.Viewer.Home.CurrentSubscription.PriceInfo.Today
-		t.mux.Unlock()
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
+// Close stops the price updates
+func (t *Tibber) Close() error {
+	close(t.done)
+	return nil
+}
+
 func (t *Tibber) CurrentPrice() (float64, error) {
 	t.mux.Lock()
 	defer t.mux.Unlock()
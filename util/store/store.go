@@ -0,0 +1,88 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Store persists learned runtime state as json file
+type Store struct {
+	mu   sync.Mutex
+	file string
+	data map[string]json.RawMessage
+}
+
+// Instance is the global store. It is kept in memory unless replaced by a file store.
+var Instance = New()
+
+// New creates an in-memory store
+func New() *Store {
+	return &Store{
+		data: make(map[string]json.RawMessage),
+	}
+}
+
+// NewFromFile creates a store persisted to file. A missing file is not an error.
+func NewFromFile(file string) (*Store, error) {
+	s := New()
+	s.file = file
+
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+
+	if err == nil {
+		err = json.Unmarshal(b, &s.data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed reading state %s: %w", file, err)
+	}
+
+	return s, nil
+}
+
+// Get decodes the value of key into res. It returns false if the key does not exist.
+func (s *Store) Get(key string, res interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.data[key]
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(b, res)
+}
+
+// Set stores the value of key and writes the file
+func (s *Store) Set(key string, val interface{}) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = b
+
+	if s.file == "" {
+		return nil
+	}
+
+	if b, err = json.MarshalIndent(s.data, "", "  "); err != nil {
+		return err
+	}
+
+	tmp := s.file + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.file)
+}
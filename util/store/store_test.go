@@ -0,0 +1,35 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "evcc.state.json")
+
+	s, err := NewFromFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type value struct{ Phases int }
+
+	var res value
+	if ok, err := s.Get("foo", &res); ok || err != nil {
+		t.Errorf("unexpected key: %v %v", ok, err)
+	}
+
+	if err := s.Set("foo", value{2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// reload
+	if s, err = NewFromFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := s.Get("foo", &res); !ok || err != nil || res.Phases != 2 {
+		t.Errorf("unexpected value: %+v %v %v", res, ok, err)
+	}
+}
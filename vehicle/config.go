@@ -42,6 +42,12 @@ func Types() []string {
 	return res
 }
 
+// Registered returns true if the vehicle type is registered
+func Registered(typ string) bool {
+	_, err := registry.Get(strings.ToLower(typ))
+	return err == nil
+}
+
 // NewFromConfig creates vehicle from configuration
 func NewFromConfig(typ string, other map[string]interface{}) (v api.Vehicle, err error) {
 	cc := struct {
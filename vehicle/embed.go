@@ -3,11 +3,12 @@ package vehicle
 import "github.com/evcc-io/evcc/api"
 
 type embed struct {
-	Title_       string           `mapstructure:"title"`
-	Capacity_    int64            `mapstructure:"capacity"`
-	Phases_      int              `mapstructure:"phases"`
-	Identifiers_ []string         `mapstructure:"identifiers"`
-	OnIdentify   api.ActionConfig `mapstructure:"onIdentify"`
+	Title_       string                 `mapstructure:"title"`
+	Capacity_    int64                  `mapstructure:"capacity"`
+	Phases_      int                    `mapstructure:"phases"`
+	Identifiers_ []string               `mapstructure:"identifiers"`
+	OnIdentify   api.ActionConfig       `mapstructure:"onIdentify"`
+	ChargeCurve_ []api.ChargeCurvePoint `mapstructure:"chargeCurve"`
 }
 
 // Title implements the api.Vehicle interface
@@ -34,3 +35,8 @@ func (v *embed) Identifiers() []string {
 func (v *embed) OnIdentified() api.ActionConfig {
 	return v.OnIdentify
 }
+
+// ChargeCurve implements the api.VehicleChargeCurve interface
+func (v *embed) ChargeCurve() []api.ChargeCurvePoint {
+	return v.ChargeCurve_
+}
@@ -136,3 +136,16 @@ var _ api.VehicleStopCharge = (*Provider)(nil)
 func (v *Provider) StopCharge() error {
 	return v.action(ActionCharge, ActionChargeStop)
 }
+
+var _ api.VehicleClimateControl = (*Provider)(nil)
+
+// StartClimate implements the api.VehicleClimateControl interface.
+// The target temperature is configured in the vehicle and cannot be set.
+func (v *Provider) StartClimate(_ float64) error {
+	return v.action(ActionClimatisation, ActionClimatisationStart)
+}
+
+// StopClimate implements the api.VehicleClimateControl interface
+func (v *Provider) StopClimate() error {
+	return v.action(ActionClimatisation, ActionClimatisationStop)
+}
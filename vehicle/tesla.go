@@ -211,11 +211,9 @@ func (v *Tesla) Position() (float64, float64, error) {
 	return 0, 0, err
 }
 
-var _ api.VehicleStartCharge = (*Tesla)(nil)
-
-// StartCharge implements the api.VehicleStartCharge interface
-func (v *Tesla) StartCharge() error {
-	err := v.vehicle.StartCharging()
+// wakeup executes the vehicle command and repeats it after waking up a sleeping vehicle
+func (v *Tesla) wakeup(command func() error) error {
+	err := command()
 
 	if err != nil && err.Error() == "408 Request Timeout" {
 		if _, err := v.vehicle.Wakeup(); err != nil {
@@ -230,7 +228,7 @@ func (v *Tesla) StartCharge() error {
 				return api.ErrTimeout
 			default:
 				time.Sleep(2 * time.Second)
-				if err := v.vehicle.StartCharging(); err == nil || err.Error() != "408 Request Timeout" {
+				if err := command(); err == nil || err.Error() != "408 Request Timeout" {
 					return err
 				}
 			}
@@ -240,6 +238,13 @@ func (v *Tesla) StartCharge() error {
 	return err
 }
 
+var _ api.VehicleStartCharge = (*Tesla)(nil)
+
+// StartCharge implements the api.VehicleStartCharge interface
+func (v *Tesla) StartCharge() error {
+	return v.wakeup(v.vehicle.StartCharging)
+}
+
 var _ api.VehicleStopCharge = (*Tesla)(nil)
 
 // StopCharge implements the api.VehicleStopCharge interface
@@ -253,3 +258,37 @@ func (v *Tesla) StopCharge() error {
 
 	return err
 }
+
+var _ api.VehicleChargeLimit = (*Tesla)(nil)
+
+// SetChargeLimit implements the api.VehicleChargeLimit interface
+func (v *Tesla) SetChargeLimit(soc int) error {
+	return v.vehicle.SetChargeLimit(soc)
+}
+
+var _ api.VehicleClimateControl = (*Tesla)(nil)
+
+// StartClimate implements the api.VehicleClimateControl interface
+func (v *Tesla) StartClimate(targetTemp float64) error {
+	if targetTemp > 0 {
+		if err := v.wakeup(func() error {
+			return v.vehicle.SetTemperature(targetTemp, targetTemp)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return v.wakeup(v.vehicle.StartAirConditioning)
+}
+
+// StopClimate implements the api.VehicleClimateControl interface
+func (v *Tesla) StopClimate() error {
+	err := v.vehicle.StopAirConditioning()
+
+	// ignore sleeping vehicle
+	if err != nil && err.Error() == "408 Request Timeout" {
+		err = nil
+	}
+
+	return err
+}